@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/catalogue"
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/infrastructure/postgres"
+)
+
+const importUsage = `Usage: server import [flags] <file>
+
+Loads the movie catalogue from a SQL dump (.sql) or a JSON catalogue
+(.json) in a single transaction. JSON catalogues are upserted by tmdb_id,
+so importing one again updates the existing movies. Use - to read stdin.
+
+Flags:
+`
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "run the import, report what it did, then roll it back")
+	format := flags.String("format", "", "sql or json (default: from the file extension)")
+	every := flags.Int("progress", 500, "report progress every n statements or movies (0 disables)")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), importUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one file")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if *format != "sql" && *format != "json" {
+		return fmt.Errorf("cannot tell the format of %q; pass -format sql or -format json", path)
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := postgres.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	start := time.Now()
+	opts := catalogue.Options{
+		DryRun:        *dryRun,
+		ProgressEvery: *every,
+		Progress: func(r catalogue.Report) {
+			fmt.Fprintf(os.Stderr, "%s (%s)\n", describeImport(r), time.Since(start).Round(time.Second))
+		},
+	}
+
+	importer := catalogue.NewImporter(db)
+	var report catalogue.Report
+	if *format == "sql" {
+		report, err = importer.ImportSQL(ctx, in, opts)
+	} else {
+		report, err = importer.ImportJSON(ctx, in, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("import rolled back after %s: %w", describeImport(report), err)
+	}
+
+	summary := describeImport(report)
+	if report.DryRun {
+		summary += " (dry run, rolled back)"
+	}
+	fmt.Printf("Import finished in %s: %s\n", time.Since(start).Round(time.Millisecond), summary)
+	return nil
+}
+
+func describeImport(r catalogue.Report) string {
+	if r.Statements > 0 {
+		return fmt.Sprintf("%d statements executed", r.Statements)
+	}
+	return fmt.Sprintf("%d movies inserted, %d updated", r.Inserted, r.Updated)
+}
@@ -1,62 +1,100 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 
+	"github.com/jgamaraalv/movies.git/database/migrations"
+	"github.com/jgamaraalv/movies.git/internal/catalogue"
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/handler"
+	"github.com/jgamaraalv/movies.git/internal/health"
+	"github.com/jgamaraalv/movies.git/internal/infrastructure/cache"
 	"github.com/jgamaraalv/movies.git/internal/infrastructure/postgres"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
+	"github.com/jgamaraalv/movies.git/internal/migrate"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
+// startupCheckTimeout bounds dependency probes made before serving.
+const startupCheckTimeout = 5 * time.Second
+
+// sitemapCacheEntries bounds the generated sitemaps kept in memory: the
+// index, pages.xml and one file per SITEMAP_URLS_PER_FILE movies.
+const sitemapCacheEntries = 100
+
 func main() {
-	logInstance := initializeLogger()
-
-	// Try to load .env from multiple locations
-	// Priority: current dir (.env) -> parent dir (../.env) -> grandparent dir (../../.env)
-	envLoaded := false
-	envPaths := []string{".env", "../.env", "../../.env"}
-
-	for _, path := range envPaths {
-		if err := godotenv.Load(path); err == nil {
-			envLoaded = true
-			log.Printf("Loaded .env from: %s", path)
-			break
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatalf("migrate: %v", err)
+			}
+			return
+		case "import":
+			if err := runImport(os.Args[2:]); err != nil {
+				log.Fatalf("import: %v", err)
+			}
+			return
 		}
 	}
 
-	if !envLoaded {
-		log.Printf("No .env file found in any of the expected locations. Using environment variables only.")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	if os.Getenv("JWT_SECRET") == "" {
-		log.Fatalf("JWT_SECRET environment variable is not set. Set it to a strong, unique random value.")
-	}
+	logInstance := initializeLogger(cfg.Log)
 
-	dbConnStr := os.Getenv("DATABASE_URL")
-	if dbConnStr == "" {
-		log.Fatalf("DATABASE_URL not set in environment")
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
 	}
 
-	db, err := sql.Open("postgres", dbConnStr)
+	db, err := postgres.Open(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
+
+	if cfg.Database.AutoMigrate {
+		if err := autoMigrate(db, logInstance); err != nil {
+			log.Fatalf("Failed to apply migrations: %v", err)
+		}
+	}
+
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
+		appMetrics.RegisterDB(db, "movies")
+	}
 
 	// Initialize repositories
-	movieRepo, err := postgres.NewMovieRepository(db, logInstance)
+	var movieRepo repository.MovieRepository
+	movieRepo, err = postgres.NewMovieRepository(db, logInstance)
 	if err != nil {
 		log.Fatalf("Failed to initialize movie repository: %v", err)
 	}
+	// Caches of catalogue data, dropped whenever an import commits
+	var catalogueCaches []interface{ InvalidateCatalogue(context.Context) }
+	if cfg.CatalogueCache.Enabled {
+		backend := cache.NewMemory(cfg.CatalogueCache.MaxEntries)
+		movieCache := cache.NewMovieRepository(movieRepo, backend, cfg.CatalogueCache.TTL, appMetrics, logInstance)
+		movieRepo = movieCache
+		catalogueCaches = append(catalogueCaches, movieCache)
+	}
 
 	accountRepo, err := postgres.NewAccountRepository(db, logInstance)
 	if err != nil {
@@ -64,66 +102,128 @@ func main() {
 	}
 
 	// Initialize recommendation repository (graceful: nil if pgvector not available)
-	recRepo, err := postgres.NewRecommendationRepository(db, logInstance)
+	var recRepo repository.RecommendationRepository
+	recommendations, recErr := postgres.NewRecommendationRepository(db, cfg.Recommendations, appMetrics, logInstance)
+	if recErr == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), startupCheckTimeout)
+		recErr = recommendations.CheckAvailability(ctx)
+		cancel()
+	}
+	if recErr != nil {
+		log.Printf("Warning: Recommendations unavailable: %v. Recommendations will be disabled.", recErr)
+	} else {
+		recRepo = recommendations
+	}
+
+	socialRepo, err := postgres.NewSocialRepository(db, logInstance)
+	if err != nil {
+		log.Fatalf("Failed to initialize social repository: %v", err)
+	}
+
+	feedRepo, err := postgres.NewFeedRepository(db, logInstance)
+	if err != nil {
+		log.Fatalf("Failed to initialize feed repository: %v", err)
+	}
+
+	statsRepo, err := postgres.NewStatsRepository(db, logInstance)
+	if err != nil {
+		log.Fatalf("Failed to initialize stats repository: %v", err)
+	}
+
+	sitemapRepo, err := postgres.NewSitemapRepository(db, logInstance)
 	if err != nil {
-		log.Printf("Warning: Failed to initialize recommendation repository: %v. Recommendations will be disabled.", err)
-		recRepo = nil
+		log.Fatalf("Failed to initialize sitemap repository: %v", err)
 	}
 
 	// Initialize handlers
 	movieHandler := handler.NewMovieHandler(movieRepo, recRepo, logInstance)
-	accountHandler := handler.NewAccountHandler(accountRepo, recRepo, logInstance)
+	accountHandler := handler.NewAccountHandler(accountRepo, movieRepo, recRepo, socialRepo, cfg.Auth, cfg.Recommendations, appMetrics, logInstance)
+	socialHandler := handler.NewSocialHandler(socialRepo, logInstance)
+	feedHandler := handler.NewFeedHandler(feedRepo, cfg.PublicURL, logInstance)
+	statsHandler := handler.NewStatsHandler(statsRepo, logInstance)
+	sitemapHandler := handler.NewSitemapHandler(sitemapRepo, cache.NewMemory(sitemapCacheEntries), cfg.PublicURL, cfg.Sitemap, cfg.HTTPCache.Sitemaps, logInstance)
+	catalogueCaches = append(catalogueCaches, sitemapHandler)
 
 	// Initialize SSR handler
-	ssrHandler, err := handler.NewSSRHandler(movieHandler, logInstance)
-	if err != nil {
-		log.Printf("Warning: Failed to initialize SSR handler: %v. SSR will be disabled.", err)
+	ssrHandler, ssrErr := handler.NewSSRHandler(movieHandler, cfg.PublicDir, cfg.PublicURL, cfg.SSR, appMetrics, logInstance)
+	if ssrErr != nil {
+		log.Printf("Warning: Failed to initialize SSR handler: %v. SSR will be disabled.", ssrErr)
 		ssrHandler = nil
 	}
 
-	// Health check endpoint for Docker/Kubernetes probes
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		if err := db.Ping(); err != nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			w.Write([]byte(`{"status":"unhealthy","error":"database connection failed"}`))
-			return
+	// Imports run in their own process and announce each commit with NOTIFY
+	catalogueListener := postgres.Listen(cfg.Database, catalogue.ChangesChannel, func(ctx context.Context) {
+		for _, c := range catalogueCaches {
+			c.InvalidateCatalogue(ctx)
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy"}`))
-	})
-
-	// Set up routes
-	http.HandleFunc("/api/account/register/", accountHandler.Register)
-	http.HandleFunc("/api/account/authenticate/", accountHandler.Authenticate)
-	http.HandleFunc("/api/movies/top", movieHandler.GetTopMovies)
-	http.HandleFunc("/api/movies/random", movieHandler.GetRandomMovies)
-	http.HandleFunc("/api/movies/search", movieHandler.SearchMovies)
-	http.Handle("/api/movies/recommendations",
-		accountHandler.AuthMiddleware(http.HandlerFunc(movieHandler.GetRecommendations)))
-	http.HandleFunc("/api/movies/", movieHandler.GetMovie)
-	http.HandleFunc("/api/genres", movieHandler.GetGenres)
-
-	http.Handle("/api/account/favorites/",
-		accountHandler.AuthMiddleware(http.HandlerFunc(accountHandler.GetFavorites)))
-
-	http.Handle("/api/account/watchlist/",
-		accountHandler.AuthMiddleware(http.HandlerFunc(accountHandler.GetWatchlist)))
-
-	http.Handle("/api/account/save-to-collection/",
-		accountHandler.AuthMiddleware(http.HandlerFunc(accountHandler.SaveToCollection)))
-
-	// Get public directory path (from root of project)
-	publicDir := os.Getenv("PUBLIC_DIR")
-	if publicDir == "" {
-		// Default: assume we're running from project root, or use relative path
-		publicDir = "public"
-		// Try relative path from server/cmd/api (for development)
-		if _, err := os.Stat(publicDir); os.IsNotExist(err) {
-			publicDir = "../../public"
+		logInstance.Info("Catalogue changed, caches invalidated")
+	}, logInstance)
+
+	// ready flips to false as soon as shutdown starts so probes stop routing
+	// traffic here while in-flight requests drain
+	var ready atomic.Bool
+	ready.Store(true)
+
+	// Liveness and readiness probes; /health is kept as an alias of /readyz
+	// for existing Docker health checks
+	checks := health.New()
+	checks.AddReadiness(health.Check{Name: "shutdown", Critical: true, Run: func(ctx context.Context) error {
+		if !ready.Load() {
+			return errors.New("shutting down")
+		}
+		return nil
+	}})
+	checks.AddReadiness(health.Check{Name: "database", Critical: true, Run: db.PingContext})
+	checks.AddReadiness(health.Check{Name: "recommendations", Run: func(ctx context.Context) error {
+		if recRepo == nil {
+			return health.Degraded("recommendations disabled: %v", recErr)
 		}
+		return recommendations.CheckAvailability(ctx)
+	}})
+	checks.AddReadiness(health.Check{Name: "ssr", Run: func(ctx context.Context) error {
+		if ssrHandler == nil {
+			return health.Degraded("SSR disabled: %v", ssrErr)
+		}
+		return nil
+	}})
+	checks.AddReadiness(health.Check{Name: "index_html", Run: func(ctx context.Context) error {
+		_, err := os.Stat(filepath.Join(cfg.PublicDir, "index.html"))
+		return err
+	}})
+	http.Handle("/livez", checks.LiveHandler())
+	http.Handle("/readyz", checks.ReadyHandler())
+	http.Handle("/health", checks.ReadyHandler())
+
+	// Prometheus scrape endpoint
+	if appMetrics != nil {
+		http.Handle(cfg.Metrics.Path, appMetrics.Handler())
 	}
-	publicDir, _ = filepath.Abs(publicDir)
+
+	// JSON API, mounted under /api/v1 and, deprecated, at the old /api paths
+	mountAPI(http.DefaultServeMux, apiRoutes(apiHandlers{
+		account: accountHandler,
+		movie:   movieHandler,
+		social:  socialHandler,
+		feed:    feedHandler,
+		stats:   statsHandler,
+	}, cfg.HTTPCache))
+	mountDocs(http.DefaultServeMux)
+
+	// Anything else under /api gets a JSON 404/405 instead of the SPA
+	http.Handle("/api/", handler.APIFallback(http.DefaultServeMux, "/api/"))
+
+	// Personal RSS/iCal feeds authenticate with the secret token in the URL
+	http.HandleFunc("GET /feeds/{token}/{file}", feedHandler.ServeFeed)
+
+	// robots.txt and the sitemaps search engines discover the catalogue from
+	cached := func(policy config.CachePolicy, h http.HandlerFunc) http.Handler { return handler.Cached(policy)(h) }
+	caching := cfg.HTTPCache
+	http.Handle("GET /robots.txt", cached(caching.Sitemaps, sitemapHandler.Robots))
+	// Sitemaps stream on a cache miss and set their own caching headers
+	http.HandleFunc("GET /sitemap.xml", sitemapHandler.Index)
+	http.HandleFunc("GET /sitemaps/{file}", sitemapHandler.ServeSitemap)
+
+	publicDir := cfg.PublicDir
 
 	// Helper function to check if a file exists
 	fileExists := func(path string) bool {
@@ -161,12 +261,17 @@ func main() {
 
 	// SSR routes for public pages (if SSR handler is available)
 	if ssrHandler != nil {
+		pageCache := handler.Cached(caching.Pages)
+		homePage := pageCache(http.HandlerFunc(ssrHandler.HomePage))
+		movieDetailsPage := pageCache(http.HandlerFunc(ssrHandler.MovieDetailsPage))
+		moviesPage := pageCache(http.HandlerFunc(ssrHandler.MoviesPage))
+
 		// Home page with SSR (only for exact "/" path)
 		// Note: Static file handlers are registered above, so they take precedence
 		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 			// Root path - use SSR
 			if r.URL.Path == "/" {
-				ssrHandler.HomePage(w, r)
+				homePage.ServeHTTP(w, r)
 				return
 			}
 
@@ -180,17 +285,17 @@ func main() {
 			path = strings.TrimSuffix(path, "/")
 			if path != "" && path != "search" {
 				if _, err := strconv.Atoi(path); err == nil {
-					ssrHandler.MovieDetailsPage(w, r)
+					movieDetailsPage.ServeHTTP(w, r)
 					return
 				}
 			}
 			serveStaticOrIndex(w, r)
 		})
 
-		// Movies search page with SSR
+		// Movies search and genre pages with SSR
 		http.HandleFunc("/movies", func(w http.ResponseWriter, r *http.Request) {
-			if r.URL.Query().Get("q") != "" {
-				ssrHandler.MoviesPage(w, r)
+			if query := r.URL.Query(); query.Get("q") != "" || query.Get("genre") != "" {
+				moviesPage.ServeHTTP(w, r)
 			} else {
 				serveStaticOrIndex(w, r)
 			}
@@ -205,49 +310,70 @@ func main() {
 	// Account pages always use SPA (private pages)
 	http.HandleFunc("/account/", serveStaticOrIndex)
 
-	const addr = ":8080"
-	logInstance.Info("Server starting on " + addr)
+	routes := limitRequestBody(http.DefaultServeMux, cfg.Server)
+	routes = handler.CORS(cfg.CORS, "/api/")(routes)
+	routes = handler.SecurityHeaders(cfg.Security)(routes)
+	routes = handler.Compress(cfg.Compression)(routes)
+	if appMetrics != nil {
+		routes = handler.RequestMetrics(appMetrics, http.DefaultServeMux)(routes)
+	}
+	routes = handler.RequestLogging(logInstance, http.DefaultServeMux)(routes)
+	routes = handler.RequestTracing(http.DefaultServeMux)(routes)
+	srv := newHTTPServer(cfg.Server, routes)
 
-	if err := http.ListenAndServe(addr, securityMiddleware(http.DefaultServeMux)); err != nil {
-		logInstance.Error("Server failed to start", err)
+	if err := serveUntilSignal(srv, cfg.Server, &ready, accountHandler, catalogueListener, db, shutdownTracing, logInstance); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
 
-// securityMiddleware adds security headers and limits request body size.
-func securityMiddleware(next http.Handler) http.Handler {
+// limitRequestBody caps the request body size; imports get a larger cap.
+func limitRequestBody(next http.Handler, cfg config.ServerConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("X-Frame-Options", "DENY")
-		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		w.Header().Set("X-XSS-Protection", "0")
-		r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MB
+		maxBody := cfg.MaxBodyBytes
+		if r.URL.Path == apiPrefix+"/account/import" || r.URL.Path == legacyAPIPrefix+"/account/import" {
+			maxBody = cfg.ImportMaxBodyBytes
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody)
 		next.ServeHTTP(w, r)
 	})
 }
 
-func initializeLogger() *logger.Logger {
-	// Define o caminho do arquivo de log
-	// Em produção com filesystem read-only, usa /app/logs ou /tmp
-	logPath := os.Getenv("LOG_PATH")
-	if logPath == "" {
-		// Tenta /app/logs primeiro (tmpfs em produção)
-		if _, err := os.Stat("/app/logs"); err == nil {
-			logPath = "/app/logs/movie-service.log"
-		} else if _, err := os.Stat("/tmp"); err == nil {
-			// Fallback para /tmp
-			logPath = "/tmp/movie-service.log"
-		} else {
-			// Último fallback: diretório atual (desenvolvimento)
-			logPath = "movie-service.log"
-		}
+// autoMigrate applies pending migrations before any repository touches the
+// schema. Replicas starting together wait on the migration lock. On a
+// database the catalogue has not been imported into yet it only warns, so
+// the server stays up for the import to be run against it.
+func autoMigrate(db *sql.DB, log *logger.Logger) error {
+	if err := requireBaseSchema(context.Background(), db); errors.Is(err, errNoBaseSchema) {
+		log.Warn("Skipping migrations", "reason", err.Error())
+		return nil
+	} else if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
 	}
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		log.Info("Applied migration", "migration", m.String())
+	}
+	return err
+}
 
-	logInstance, err := logger.NewLogger(logPath)
+func initializeLogger(cfg config.LogConfig) *logger.Logger {
+	opts := logger.Options{
+		Level:    cfg.Level,
+		Format:   cfg.Format,
+		Outputs:  cfg.Outputs,
+		FilePath: cfg.Path,
+		Redact:   cfg.Redact,
+	}
+	logInstance, err := logger.New(opts)
 	if err != nil {
-		log.Printf("Warning: Failed to initialize file logger at %s: %v. Using stdout only.", logPath, err)
-		// Fallback: cria um logger que só usa stdout
-		logInstance, _ = logger.NewLogger("/dev/null")
+		log.Printf("Warning: Failed to initialize logger with file %s: %v. Using stdout only.", cfg.Path, err)
+		// Fallback: log to stdout only, e.g. when the log directory is read-only
+		opts.Outputs = []string{logger.OutputStdout}
+		logInstance, _ = logger.New(opts)
 	}
 	return logInstance
 }
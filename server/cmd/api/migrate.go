@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/database/migrations"
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/infrastructure/postgres"
+	"github.com/jgamaraalv/movies.git/internal/migrate"
+)
+
+const migrateUsage = `Usage: server migrate <command>
+
+Commands:
+  up             apply all pending migrations
+  down [n]       revert the last n applied migrations (default 1)
+  status         list migrations and when they were applied
+  create <name>  add an empty up/down pair to -dir
+
+Flags:
+`
+
+// runMigrate implements the migrate subcommand. Database commands use the
+// same configuration as the server.
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := flags.String("dir", "database/migrations", "migrations directory used by create")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing command")
+	}
+
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	if command == "create" {
+		if len(rest) != 1 {
+			return errors.New("create needs exactly one migration name")
+		}
+		paths, err := migrate.Create(*dir, rest[0])
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		return err
+	}
+
+	switch command {
+	case "up", "down", "status":
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := postgres.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	switch command {
+	case "up":
+		if err := requireBaseSchema(ctx, db); err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Println("Applied", m)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(rest) > 0 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil || steps < 1 {
+				return fmt.Errorf("down: %q is not a positive number of steps", rest[0])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Println("Reverted", m)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			if s.Missing {
+				applied += " (no file)"
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// errNoBaseSchema is returned by requireBaseSchema. The migrations extend
+// the movies and users tables, which only the catalogue import creates.
+var errNoBaseSchema = errors.New("the movies and users tables do not exist yet: import the catalogue (server import database/import/database-dump.sql) before applying migrations")
+
+// requireBaseSchema checks that the catalogue import has created the tables
+// the migrations build on.
+func requireBaseSchema(ctx context.Context, db *sql.DB) error {
+	var ok bool
+	err := db.QueryRowContext(ctx,
+		`SELECT to_regclass('public.movies') IS NOT NULL AND to_regclass('public.users') IS NOT NULL`).Scan(&ok)
+	if err != nil {
+		return fmt.Errorf("check base schema: %w", err)
+	}
+	if !ok {
+		return errNoBaseSchema
+	}
+	return nil
+}
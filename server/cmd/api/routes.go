@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/handler"
+	"github.com/jgamaraalv/movies.git/internal/openapi"
+)
+
+const (
+	apiPrefix = "/api/v1"
+	// legacyAPIPrefix serves the unversioned paths clients used before
+	// /api/v1, with Deprecation headers pointing at the new ones.
+	legacyAPIPrefix = "/api"
+)
+
+// apiRoute is a JSON API endpoint. path is relative to the API prefix and
+// may contain {name} wildcards read with r.PathValue.
+type apiRoute struct {
+	method  string
+	path    string
+	handler http.Handler
+	// legacy is the old path under legacyAPIPrefix, when it was not path.
+	legacy string
+}
+
+// mountAPI registers every route under apiPrefix plus its deprecated alias,
+// and returns the apiPrefix patterns (the ones OpenAPI documents).
+func mountAPI(mux *http.ServeMux, routes []apiRoute) []string {
+	patterns := make([]string, 0, len(routes))
+	for _, route := range routes {
+		pattern := route.method + " " + apiPrefix + route.path
+		mux.Handle(pattern, route.handler)
+		patterns = append(patterns, pattern)
+
+		deprecated := handler.Deprecated(legacyAPIPrefix, apiPrefix, route.handler)
+		legacy := route.legacy
+		if legacy == "" {
+			legacy = route.path
+		}
+		if trimmed, ok := strings.CutSuffix(legacy, "/"); ok {
+			// Old routes were prefix patterns; accept them with and
+			// without the trailing slash, but nothing below them
+			mux.Handle(route.method+" "+legacyAPIPrefix+legacy+"{$}", deprecated)
+			legacy = trimmed
+		}
+		mux.Handle(route.method+" "+legacyAPIPrefix+legacy, deprecated)
+	}
+	return patterns
+}
+
+// apiHandlers are the handlers the API routes dispatch to.
+type apiHandlers struct {
+	account *handler.AccountHandler
+	movie   *handler.MovieHandler
+	social  *handler.SocialHandler
+	feed    *handler.FeedHandler
+	stats   *handler.StatsHandler
+}
+
+// apiRoutes is the JSON API, which openapi.json documents. The OpenAPI test
+// checks the two against each other.
+func apiRoutes(h apiHandlers, caching config.HTTPCacheConfig) []apiRoute {
+	auth := func(next http.HandlerFunc) http.Handler { return h.account.AuthMiddleware(next) }
+	cached := func(policy config.CachePolicy, next http.HandlerFunc) http.Handler {
+		return handler.Cached(policy)(next)
+	}
+	return []apiRoute{
+		{method: http.MethodPost, path: "/account/register", legacy: "/account/register/", handler: http.HandlerFunc(h.account.Register)},
+		{method: http.MethodPost, path: "/account/authenticate", legacy: "/account/authenticate/", handler: http.HandlerFunc(h.account.Authenticate)},
+		{method: http.MethodGet, path: "/account/favorites", legacy: "/account/favorites/", handler: auth(h.account.GetFavorites)},
+		{method: http.MethodGet, path: "/account/watchlist", legacy: "/account/watchlist/", handler: auth(h.account.GetWatchlist)},
+		{method: http.MethodPost, path: "/account/save-to-collection", legacy: "/account/save-to-collection/", handler: auth(h.account.SaveToCollection)},
+		{method: http.MethodPost, path: "/account/import", handler: auth(h.account.ImportCollection)},
+		{method: http.MethodGet, path: "/account/export", handler: auth(h.account.ExportCollection)},
+		{method: http.MethodGet, path: "/account/stats", handler: auth(h.stats.GetStats)},
+		{method: http.MethodGet, path: "/account/feed", handler: auth(h.social.GetFeed)},
+		{method: http.MethodPost, path: "/account/feed-token", handler: auth(h.feed.CreateFeedToken)},
+		{method: http.MethodDelete, path: "/account/feed-token", handler: auth(h.feed.RevokeFeedToken)},
+		{method: http.MethodGet, path: "/movies/top", handler: cached(caching.TopMovies, h.movie.GetTopMovies)},
+		{method: http.MethodGet, path: "/movies/random", handler: cached(caching.RandomMovies, h.movie.GetRandomMovies)},
+		{method: http.MethodGet, path: "/movies/search", handler: cached(caching.Search, h.movie.SearchMovies)},
+		{method: http.MethodGet, path: "/movies/recommendations", handler: auth(h.movie.GetRecommendations)},
+		{method: http.MethodGet, path: "/movies/{id}", handler: cached(caching.Movie, h.movie.GetMovie)},
+		{method: http.MethodGet, path: "/genres", handler: cached(caching.Genres, h.movie.GetGenres)},
+		{method: http.MethodPost, path: "/users/{id}/follow", handler: auth(h.social.Follow)},
+		{method: http.MethodDelete, path: "/users/{id}/follow", handler: auth(h.social.Unfollow)},
+		{method: http.MethodGet, path: "/users/{id}/followers", handler: auth(h.social.GetFollowers)},
+		{method: http.MethodGet, path: "/users/{id}/following", handler: auth(h.social.GetFollowing)},
+	}
+}
+
+// mountDocs serves the OpenAPI document and its docs page, and returns
+// their patterns.
+func mountDocs(mux *http.ServeMux) []string {
+	mux.Handle("GET /api/openapi.json", openapi.Handler())
+	mux.Handle("GET /api/docs", handler.ContentSecurityPolicy(openapi.DocsCSP)(openapi.DocsHandler()))
+	return []string{"GET /api/openapi.json", "GET /api/docs"}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/handler"
+	"github.com/jgamaraalv/movies.git/internal/openapi"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// testAPIMux mounts the API routes, plus extra, and the docs on a new mux
+// and returns it with the patterns openapi.json has to document. The
+// handlers have no repositories: routing never calls them.
+func testAPIMux(t *testing.T, extra ...apiRoute) (*http.ServeMux, []string) {
+	t.Helper()
+	log, err := logger.New(logger.Options{Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := apiHandlers{
+		account: handler.NewAccountHandler(nil, nil, nil, nil, config.AuthConfig{}, config.RecommendationsConfig{}, nil, log),
+		movie:   handler.NewMovieHandler(nil, nil, log),
+		social:  handler.NewSocialHandler(nil, log),
+		feed:    handler.NewFeedHandler(nil, "http://localhost:8080", log),
+		stats:   handler.NewStatsHandler(nil, log),
+	}
+
+	mux := http.NewServeMux()
+	patterns := mountAPI(mux, append(apiRoutes(h, config.HTTPCacheConfig{}), extra...))
+	patterns = append(patterns, mountDocs(mux)...)
+	return mux, patterns
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	mux, patterns := testAPIMux(t)
+	if err := openapi.Check(mux, patterns); err != nil {
+		t.Errorf("openapi.json is out of date:\n%v", err)
+	}
+}
+
+func TestOpenAPICheckReportsUndocumentedRoute(t *testing.T) {
+	mux, patterns := testAPIMux(t, apiRoute{
+		method:  http.MethodGet,
+		path:    "/undocumented",
+		handler: http.NotFoundHandler(),
+	})
+	err := openapi.Check(mux, patterns)
+	if err == nil || !strings.Contains(err.Error(), "route GET /api/v1/undocumented is not documented") {
+		t.Errorf("Check() = %v, want it to report the undocumented route", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/handler"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+func newHTTPServer(cfg config.ServerConfig, h http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           h,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    1 << 20,
+	}
+}
+
+// serveUntilSignal runs srv until SIGTERM or SIGINT, then shuts down in
+// order: readiness goes unhealthy, the drain period elapses, in-flight
+// requests finish, background recommendation refreshes finish, and finally
+// the catalogue listener and the database are closed, buffered spans are
+// flushed and the logger is closed.
+func serveUntilSignal(srv *http.Server, cfg config.ServerConfig, ready *atomic.Bool, accountHandler *handler.AccountHandler, catalogueListener io.Closer, db *sql.DB, shutdownTracing func(context.Context) error, logInstance *logger.Logger) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logInstance.Info("Server starting", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// The listener failed before any signal; still release resources
+		logInstance.Error("Server failed", err)
+	case <-ctx.Done():
+		stop()
+		logInstance.Info("Shutdown signal received, draining", "drain", cfg.ShutdownDrain.String())
+		ready.Store(false)
+		time.Sleep(cfg.ShutdownDrain)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+
+		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+			logInstance.Error("HTTP server did not shut down cleanly", shutdownErr)
+			err = shutdownErr
+		}
+		if drainErr := accountHandler.DrainBackground(shutdownCtx); drainErr != nil {
+			logInstance.Error("Background tasks did not finish before shutdown timeout", drainErr)
+		}
+		logInstance.Info("HTTP server stopped")
+	}
+
+	if closeErr := catalogueListener.Close(); closeErr != nil {
+		logInstance.Error("Failed to close catalogue listener", closeErr)
+	}
+	if closeErr := db.Close(); closeErr != nil {
+		logInstance.Error("Failed to close database", closeErr)
+	}
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelFlush()
+	if traceErr := shutdownTracing(flushCtx); traceErr != nil {
+		logInstance.Error("Failed to flush traces", traceErr)
+	}
+	logInstance.Info("Shutdown complete")
+	logInstance.Close()
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
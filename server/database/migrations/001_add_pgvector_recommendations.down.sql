@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS user_recommendations;
+DROP TABLE IF EXISTS user_embeddings;
+DROP TABLE IF EXISTS movie_embeddings;
+
+-- The vector extension is left installed; other databases on the server
+-- may rely on it.
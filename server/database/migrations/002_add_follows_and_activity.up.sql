@@ -0,0 +1,22 @@
+-- Directed follow graph between users
+CREATE TABLE IF NOT EXISTS user_follows (
+    follower_id  int4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id  int4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    time_created timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows (followee_id);
+
+-- Append-only stream of user activity (favorites, ratings, reviews, list updates)
+CREATE TABLE IF NOT EXISTS activity_events (
+    id           bigserial PRIMARY KEY,
+    user_id      int4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type   text NOT NULL,
+    movie_id     int4 REFERENCES movies(id) ON DELETE CASCADE,
+    payload      jsonb NOT NULL DEFAULT '{}',
+    time_created timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_user ON activity_events (user_id, id DESC);
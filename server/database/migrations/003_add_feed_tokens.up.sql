@@ -0,0 +1,7 @@
+-- Secret per-user tokens for RSS/iCal feed URLs. Only a SHA-256 hash of the
+-- token is stored; rotating or deleting the row revokes the old URLs.
+CREATE TABLE IF NOT EXISTS user_feed_tokens (
+    user_id      int4 PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash   text NOT NULL UNIQUE,
+    time_created timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
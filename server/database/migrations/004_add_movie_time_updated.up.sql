@@ -0,0 +1,4 @@
+-- When a movie's catalogue data last changed, published as the sitemap
+-- lastmod. Movies imported before this column existed start at the time of
+-- the migration.
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS time_updated timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
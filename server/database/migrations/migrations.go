@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned schema changes applied by the
+// server's migrate command. Each change is a pair of files named
+// NNN_description.up.sql and NNN_description.down.sql; statements are
+// written with IF [NOT] EXISTS so databases migrated by hand before the
+// runner existed can adopt it.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
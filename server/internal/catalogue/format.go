@@ -0,0 +1,126 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Movie is one entry of a JSON catalogue. Genres, keywords and cast are
+// matched by name and created when missing; tmdb_id identifies the movie
+// across imports.
+type Movie struct {
+	TMDBID      int        `json:"tmdb_id"`
+	Title       string     `json:"title"`
+	Tagline     *string    `json:"tagline"`
+	ReleaseYear int        `json:"release_year"`
+	Overview    *string    `json:"overview"`
+	Score       *float32   `json:"score"`
+	Popularity  *float32   `json:"popularity"`
+	Language    *string    `json:"language"`
+	PosterURL   *string    `json:"poster_url"`
+	TrailerURL  *string    `json:"trailer_url"`
+	Genres      []string   `json:"genres"`
+	Keywords    []string   `json:"keywords"`
+	Cast        []CastItem `json:"cast"`
+}
+
+type CastItem struct {
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	ImageURL  *string `json:"image_url"`
+}
+
+func (m Movie) validate() error {
+	var problems []string
+	if m.TMDBID <= 0 {
+		problems = append(problems, "tmdb_id must be positive")
+	}
+	if strings.TrimSpace(m.Title) == "" {
+		problems = append(problems, "title is required")
+	}
+	for i, actor := range m.Cast {
+		if strings.TrimSpace(actor.FirstName+actor.LastName) == "" {
+			problems = append(problems, fmt.Sprintf("cast[%d] has no name", i))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// MovieDecoder streams movies from a JSON catalogue, either a bare array or
+// an object with a "movies" array, without holding the whole file in memory.
+type MovieDecoder struct {
+	dec   *json.Decoder
+	index int
+	err   error
+	movie Movie
+}
+
+func NewMovieDecoder(r io.Reader) *MovieDecoder {
+	d := &MovieDecoder{dec: json.NewDecoder(r)}
+	d.err = d.openArray()
+	return d
+}
+
+func (d *MovieDecoder) openArray() error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('['):
+		return nil
+	case json.Delim('{'):
+		for d.dec.More() {
+			key, err := d.dec.Token()
+			if err != nil {
+				return err
+			}
+			if key == "movies" {
+				tok, err := d.dec.Token()
+				if err != nil {
+					return err
+				}
+				if tok != json.Delim('[') {
+					return errors.New(`catalogue: "movies" must be an array`)
+				}
+				return nil
+			}
+			var skip json.RawMessage
+			if err := d.dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+		return errors.New(`catalogue: no "movies" array found`)
+	default:
+		return errors.New("catalogue: expected an array of movies or an object with a movies array")
+	}
+}
+
+// Next decodes the next movie. It returns false at the end of the array or
+// on error; see Err.
+func (d *MovieDecoder) Next() bool {
+	if d.err != nil || !d.dec.More() {
+		return false
+	}
+	d.movie = Movie{}
+	if err := d.dec.Decode(&d.movie); err != nil {
+		d.err = fmt.Errorf("catalogue: movie #%d: %w", d.index+1, err)
+		return false
+	}
+	d.index++
+	if err := d.movie.validate(); err != nil {
+		d.err = fmt.Errorf("catalogue: movie #%d (tmdb_id %d): %w", d.index, d.movie.TMDBID, err)
+		return false
+	}
+	return true
+}
+
+func (d *MovieDecoder) Movie() Movie { return d.movie }
+
+func (d *MovieDecoder) Err() error { return d.err }
@@ -0,0 +1,318 @@
+// Package catalogue loads the movie catalogue into Postgres, either by
+// replaying a SQL dump statement by statement or by upserting a JSON
+// catalogue keyed on tmdb_id. Each import runs in a single transaction, so
+// a failure (or a dry run) leaves the database untouched.
+package catalogue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// importLockID serializes concurrent imports with pg_advisory_xact_lock.
+const importLockID int64 = 7_304_200_118
+
+type Options struct {
+	// DryRun performs the whole import and then rolls it back.
+	DryRun bool
+	// Progress, when set, is called every ProgressEvery statements or
+	// movies with the running totals.
+	Progress      func(Report)
+	ProgressEvery int
+}
+
+type Report struct {
+	Statements int  `json:"statements,omitempty"`
+	Inserted   int  `json:"inserted,omitempty"`
+	Updated    int  `json:"updated,omitempty"`
+	DryRun     bool `json:"dry_run"`
+}
+
+// ChangesChannel is the channel every committed import notifies, so the
+// API servers, which run in other processes, can drop cached catalogue
+// data.
+const ChangesChannel = "catalogue_changes"
+
+type Importer struct {
+	db *sql.DB
+}
+
+func NewImporter(db *sql.DB) *Importer {
+	return &Importer{db: db}
+}
+
+// ImportSQL executes every statement of a SQL dump in one transaction. The
+// dump is streamed, so its size is not bounded by memory. Unlike JSON
+// catalogues, re-running a dump is only safe if its statements are.
+func (im *Importer) ImportSQL(ctx context.Context, r io.Reader, opts Options) (Report, error) {
+	report := Report{DryRun: opts.DryRun}
+	err := im.inTx(ctx, opts, func(tx *sql.Tx) error {
+		scanner := NewStatementScanner(r)
+		for scanner.Scan() {
+			if _, err := tx.ExecContext(ctx, scanner.Statement()); err != nil {
+				return fmt.Errorf("statement ending on line %d: %w\n%s", scanner.Line(), err, abbreviate(scanner.Statement()))
+			}
+			report.Statements++
+			opts.progress(report, report.Statements)
+		}
+		return scanner.Err()
+	})
+	return report, err
+}
+
+// ImportJSON upserts every movie of a JSON catalogue: movies already
+// present (by tmdb_id) are updated and their genres, keywords and cast
+// replaced, so importing the same catalogue twice changes nothing.
+func (im *Importer) ImportJSON(ctx context.Context, r io.Reader, opts Options) (Report, error) {
+	report := Report{DryRun: opts.DryRun}
+	err := im.inTx(ctx, opts, func(tx *sql.Tx) error {
+		up, err := newUpserter(ctx, tx)
+		if err != nil {
+			return err
+		}
+		defer up.close()
+
+		movies := NewMovieDecoder(r)
+		for movies.Next() {
+			movie := movies.Movie()
+			inserted, err := up.movie(ctx, movie)
+			if err != nil {
+				return fmt.Errorf("movie tmdb_id %d (%s): %w", movie.TMDBID, movie.Title, err)
+			}
+			if inserted {
+				report.Inserted++
+			} else {
+				report.Updated++
+			}
+			opts.progress(report, report.Inserted+report.Updated)
+		}
+		return movies.Err()
+	})
+	return report, err
+}
+
+func (o Options) progress(report Report, done int) {
+	if o.Progress != nil && o.ProgressEvery > 0 && done%o.ProgressEvery == 0 {
+		o.Progress(report)
+	}
+}
+
+// inTx runs fn in a transaction that holds the import lock and is exempt
+// from the statement timeout, then commits it (or rolls back on a dry run).
+func (im *Importer) inTx(ctx context.Context, opts Options, fn func(tx *sql.Tx) error) error {
+	tx, err := im.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SET LOCAL statement_timeout = 0`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, importLockID); err != nil {
+		return fmt.Errorf("acquire import lock: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	// Postgres delivers the notification when the transaction commits, so
+	// dry runs and failed imports notify no one
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, '')`, ChangesChannel); err != nil {
+		return fmt.Errorf("notify %s: %w", ChangesChannel, err)
+	}
+	if opts.DryRun {
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+// upserter holds the prepared statements of a JSON import and caches the
+// ids of genres, keywords and actors it has already resolved.
+type upserter struct {
+	stmts    map[string]*sql.Stmt
+	genres   map[string]int
+	keywords map[string]int
+	actors   map[[2]string]int
+}
+
+var upsertQueries = map[string]string{
+	// time_updated only moves when the movie's data changes, so re-importing
+	// an unchanged export leaves the sitemap lastmod alone
+	"updateMovie": `
+		UPDATE movies SET title = $2, tagline = $3, release_year = $4, overview = $5,
+			score = $6, popularity = $7, language = $8, poster_url = $9, trailer_url = $10,
+			time_updated = CASE
+				WHEN (title, tagline, release_year, overview, score, popularity, language, poster_url, trailer_url)
+					IS DISTINCT FROM ($2, $3, $4, $5, $6, $7, $8, $9, $10)
+				THEN CURRENT_TIMESTAMP ELSE time_updated END
+		WHERE tmdb_id = $1
+		RETURNING id`,
+	"insertMovie": `
+		INSERT INTO movies (tmdb_id, title, tagline, release_year, overview,
+			score, popularity, language, poster_url, trailer_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+	"findGenre":        `SELECT id FROM genres WHERE name = $1 LIMIT 1`,
+	"insertGenre":      `INSERT INTO genres (name) VALUES ($1) RETURNING id`,
+	"findKeyword":      `SELECT id FROM keywords WHERE word = $1 LIMIT 1`,
+	"insertKeyword":    `INSERT INTO keywords (word) VALUES ($1) RETURNING id`,
+	"findActor":        `SELECT id FROM actors WHERE first_name = $1 AND last_name = $2 LIMIT 1`,
+	"insertActor":      `INSERT INTO actors (first_name, last_name, image_url) VALUES ($1, $2, $3) RETURNING id`,
+	"updateActorImage": `UPDATE actors SET image_url = $2 WHERE id = $1 AND image_url IS DISTINCT FROM $2`,
+	"clearGenres":      `DELETE FROM movie_genres WHERE movie_id = $1`,
+	"clearKeywords":    `DELETE FROM movie_keywords WHERE movie_id = $1`,
+	"clearCast":        `DELETE FROM movie_cast WHERE movie_id = $1`,
+	"addGenre":         `INSERT INTO movie_genres (movie_id, genre_id) VALUES ($1, $2)`,
+	"addKeyword":       `INSERT INTO movie_keywords (movie_id, keyword_id) VALUES ($1, $2)`,
+	"addCast":          `INSERT INTO movie_cast (movie_id, actor_id) VALUES ($1, $2)`,
+}
+
+func newUpserter(ctx context.Context, tx *sql.Tx) (*upserter, error) {
+	up := &upserter{
+		stmts:    make(map[string]*sql.Stmt, len(upsertQueries)),
+		genres:   make(map[string]int),
+		keywords: make(map[string]int),
+		actors:   make(map[[2]string]int),
+	}
+	for name, query := range upsertQueries {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			up.close()
+			return nil, fmt.Errorf("prepare %s: %w", name, err)
+		}
+		up.stmts[name] = stmt
+	}
+	return up, nil
+}
+
+func (up *upserter) close() {
+	for _, stmt := range up.stmts {
+		stmt.Close()
+	}
+}
+
+// movie writes m and replaces its relations, reporting whether it was new.
+func (up *upserter) movie(ctx context.Context, m Movie) (bool, error) {
+	args := []interface{}{m.TMDBID, m.Title, m.Tagline, m.ReleaseYear, m.Overview,
+		m.Score, m.Popularity, m.Language, m.PosterURL, m.TrailerURL}
+
+	inserted := false
+	var movieID int
+	err := up.stmts["updateMovie"].QueryRowContext(ctx, args...).Scan(&movieID)
+	if errors.Is(err, sql.ErrNoRows) {
+		inserted = true
+		err = up.stmts["insertMovie"].QueryRowContext(ctx, args...).Scan(&movieID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !inserted {
+		for _, name := range []string{"clearGenres", "clearKeywords", "clearCast"} {
+			if _, err := up.stmts[name].ExecContext(ctx, movieID); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	for _, name := range dedupe(m.Genres) {
+		id, err := up.lookup(ctx, up.genres, name, "findGenre", "insertGenre")
+		if err != nil {
+			return false, err
+		}
+		if _, err := up.stmts["addGenre"].ExecContext(ctx, movieID, id); err != nil {
+			return false, err
+		}
+	}
+	for _, word := range dedupe(m.Keywords) {
+		id, err := up.lookup(ctx, up.keywords, word, "findKeyword", "insertKeyword")
+		if err != nil {
+			return false, err
+		}
+		if _, err := up.stmts["addKeyword"].ExecContext(ctx, movieID, id); err != nil {
+			return false, err
+		}
+	}
+
+	seen := make(map[int]bool, len(m.Cast))
+	for _, actor := range m.Cast {
+		id, err := up.actor(ctx, actor)
+		if err != nil {
+			return false, err
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := up.stmts["addCast"].ExecContext(ctx, movieID, id); err != nil {
+			return false, err
+		}
+	}
+	return inserted, nil
+}
+
+func (up *upserter) lookup(ctx context.Context, cache map[string]int, value, find, insert string) (int, error) {
+	if id, ok := cache[value]; ok {
+		return id, nil
+	}
+	var id int
+	err := up.stmts[find].QueryRowContext(ctx, value).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = up.stmts[insert].QueryRowContext(ctx, value).Scan(&id)
+	}
+	if err != nil {
+		return 0, err
+	}
+	cache[value] = id
+	return id, nil
+}
+
+// actor resolves an actor by name, refreshing the image when the
+// catalogue has one.
+func (up *upserter) actor(ctx context.Context, a CastItem) (int, error) {
+	key := [2]string{strings.TrimSpace(a.FirstName), strings.TrimSpace(a.LastName)}
+	if id, ok := up.actors[key]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := up.stmts["findActor"].QueryRowContext(ctx, key[0], key[1]).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = up.stmts["insertActor"].QueryRowContext(ctx, key[0], key[1], a.ImageURL).Scan(&id)
+	case err == nil && a.ImageURL != nil:
+		_, err = up.stmts["updateActorImage"].ExecContext(ctx, id, a.ImageURL)
+	}
+	if err != nil {
+		return 0, err
+	}
+	up.actors[key] = id
+	return id, nil
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+func abbreviate(stmt string) string {
+	const max = 200
+	if len(stmt) <= max {
+		return stmt
+	}
+	return stmt[:max] + "..."
+}
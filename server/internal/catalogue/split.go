@@ -0,0 +1,281 @@
+package catalogue
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ErrCopyFromStdin is returned for pg_dump COPY blocks, whose inline data
+// cannot be sent as a statement. Dump with --inserts instead.
+var ErrCopyFromStdin = errors.New("COPY ... FROM stdin is not supported; create the dump with pg_dump --inserts")
+
+// StatementScanner reads SQL statements one at a time from a stream, in the
+// manner of bufio.Scanner. It understands single-quoted and E'...' strings,
+// quoted identifiers, dollar-quoted bodies, and line and (nested) block
+// comments, so semicolons inside any of them do not end a statement.
+// Comments are dropped from the returned statements.
+type StatementScanner struct {
+	r    *bufio.Reader
+	stmt strings.Builder
+	text string
+	line int
+	end  int
+	err  error
+}
+
+func NewStatementScanner(r io.Reader) *StatementScanner {
+	return &StatementScanner{r: bufio.NewReaderSize(r, 64<<10), line: 1}
+}
+
+// Statement returns the statement read by the last successful Scan, without
+// its terminating semicolon.
+func (s *StatementScanner) Statement() string { return s.text }
+
+// Line is the input line on which the current statement ends.
+func (s *StatementScanner) Line() int { return s.end }
+
+func (s *StatementScanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// Scan advances to the next non-empty statement. It returns false at the
+// end of input or on error; see Err.
+func (s *StatementScanner) Scan() bool {
+	for s.err == nil {
+		s.stmt.Reset()
+		done := s.readStatement()
+		text := strings.TrimSpace(s.stmt.String())
+		if text == "" {
+			if done {
+				continue
+			}
+			return false
+		}
+		if isCopyFromStdin(text) {
+			s.err = ErrCopyFromStdin
+			return false
+		}
+		s.text = text
+		return true
+	}
+	return false
+}
+
+// readStatement copies input into s.stmt up to a top-level semicolon. It
+// reports whether a semicolon was found; false means input ended or failed.
+func (s *StatementScanner) readStatement() bool {
+	for {
+		c, ok := s.next()
+		if !ok {
+			return false
+		}
+		switch c {
+		case ';':
+			s.end = s.line
+			return true
+		case '\'':
+			escapes := s.endsWithEscapePrefix()
+			s.write(c)
+			if !s.copyQuoted('\'', escapes) {
+				return false
+			}
+		case '"':
+			s.write(c)
+			if !s.copyQuoted('"', false) {
+				return false
+			}
+		case '-':
+			if s.peek() == '-' {
+				s.skipLine()
+				s.stmt.WriteRune(' ')
+				continue
+			}
+			s.write(c)
+		case '/':
+			if s.peek() == '*' {
+				s.next()
+				if !s.skipBlockComment() {
+					return false
+				}
+				s.stmt.WriteRune(' ')
+				continue
+			}
+			s.write(c)
+		case '$':
+			s.write(c)
+			if tag, ok := s.readDollarTag(); ok {
+				if !s.copyDollarQuoted(tag) {
+					return false
+				}
+			}
+		default:
+			s.write(c)
+		}
+	}
+}
+
+// write adds c to the statement, noting the line of its last non-space
+// character for a statement that input ends without a semicolon.
+func (s *StatementScanner) write(c rune) {
+	s.stmt.WriteRune(c)
+	if !unicode.IsSpace(c) {
+		s.end = s.line
+	}
+}
+
+func (s *StatementScanner) next() (rune, bool) {
+	c, _, err := s.r.ReadRune()
+	if err != nil {
+		s.err = err
+		return 0, false
+	}
+	if c == '\n' {
+		s.line++
+	}
+	return c, true
+}
+
+func (s *StatementScanner) peek() rune {
+	c, _, err := s.r.ReadRune()
+	if err != nil {
+		return 0
+	}
+	s.r.UnreadRune()
+	return c
+}
+
+// endsWithEscapePrefix reports whether the quote about to be read opens an
+// E'...' string, where backslash escapes the next character.
+func (s *StatementScanner) endsWithEscapePrefix() bool {
+	text := s.stmt.String()
+	if text == "" {
+		return false
+	}
+	last := rune(text[len(text)-1])
+	if last != 'E' && last != 'e' {
+		return false
+	}
+	return len(text) == 1 || !isIdentRune(rune(text[len(text)-2]))
+}
+
+// copyQuoted copies through the closing quote; a doubled quote is an
+// escaped one.
+func (s *StatementScanner) copyQuoted(quote rune, backslashEscapes bool) bool {
+	for {
+		c, ok := s.next()
+		if !ok {
+			return false
+		}
+		s.write(c)
+		switch {
+		case backslashEscapes && c == '\\':
+			escaped, ok := s.next()
+			if !ok {
+				return false
+			}
+			s.write(escaped)
+		case c == quote:
+			if s.peek() != quote {
+				return true
+			}
+			s.next()
+			s.write(quote)
+		}
+	}
+}
+
+func (s *StatementScanner) skipLine() {
+	for {
+		c, ok := s.next()
+		if !ok || c == '\n' {
+			return
+		}
+	}
+}
+
+func (s *StatementScanner) skipBlockComment() bool {
+	depth := 1
+	for depth > 0 {
+		c, ok := s.next()
+		if !ok {
+			return false
+		}
+		switch {
+		case c == '*' && s.peek() == '/':
+			s.next()
+			depth--
+		case c == '/' && s.peek() == '*':
+			s.next()
+			depth++
+		}
+	}
+	return true
+}
+
+// readDollarTag reads the rest of a $tag$ opener after the first '$'. When
+// the input is not a dollar quote (e.g. a $1 parameter), nothing is
+// consumed beyond what is copied to the statement.
+func (s *StatementScanner) readDollarTag() (string, bool) {
+	var tag strings.Builder
+	for {
+		c := s.peek()
+		switch {
+		case c == '$':
+			s.next()
+			s.write(c)
+			return tag.String(), true
+		case tag.Len() == 0 && unicode.IsDigit(c):
+			return "", false
+		case isIdentRune(c):
+			s.next()
+			s.write(c)
+			tag.WriteRune(c)
+		default:
+			return "", false
+		}
+	}
+}
+
+func (s *StatementScanner) copyDollarQuoted(tag string) bool {
+	closing := "$" + tag + "$"
+	var window strings.Builder
+	for {
+		c, ok := s.next()
+		if !ok {
+			return false
+		}
+		s.write(c)
+		window.WriteRune(c)
+		if strings.HasSuffix(window.String(), closing) {
+			return true
+		}
+		if window.Len() > 4*len(closing) {
+			keep := window.String()[window.Len()-len(closing):]
+			window.Reset()
+			window.WriteString(keep)
+		}
+	}
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func isCopyFromStdin(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(stmt))
+	if len(fields) == 0 || fields[0] != "COPY" {
+		return false
+	}
+	for i := 1; i+1 < len(fields); i++ {
+		if fields[i] == "FROM" && strings.HasPrefix(fields[i+1], "STDIN") {
+			return true
+		}
+	}
+	return false
+}
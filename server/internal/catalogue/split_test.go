@@ -0,0 +1,172 @@
+package catalogue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type scannedStatement struct {
+	text string
+	line int
+}
+
+func TestStatementScanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []scannedStatement
+		wantErr error
+	}{
+		{
+			name:  "statements and line numbers",
+			input: "SELECT 1;\nSELECT 2;\n\nSELECT\n  3;\n",
+			want: []scannedStatement{
+				{"SELECT 1", 1},
+				{"SELECT 2", 2},
+				{"SELECT\n  3", 5},
+			},
+		},
+		{
+			name:  "final statement without semicolon",
+			input: "SELECT 1;\nSELECT 2\n",
+			want: []scannedStatement{
+				{"SELECT 1", 1},
+				{"SELECT 2", 2},
+			},
+		},
+		{
+			name:  "final statement followed by a comment",
+			input: "SELECT 1\n-- done\n\n",
+			want:  []scannedStatement{{"SELECT 1", 1}},
+		},
+		{
+			name:  "empty statements are skipped",
+			input: ";;\n  ;\nSELECT 1;;",
+			want:  []scannedStatement{{"SELECT 1", 3}},
+		},
+		{
+			name:  "semicolon in single-quoted string",
+			input: "INSERT INTO t VALUES ('a;b');SELECT 2;",
+			want: []scannedStatement{
+				{"INSERT INTO t VALUES ('a;b')", 1},
+				{"SELECT 2", 1},
+			},
+		},
+		{
+			name:  "doubled quote inside string",
+			input: "SELECT 'it''s; fine';",
+			want:  []scannedStatement{{"SELECT 'it''s; fine'", 1}},
+		},
+		{
+			name:  "backslash is literal in standard strings",
+			input: `SELECT 'C:\';SELECT 2;`,
+			want: []scannedStatement{
+				{`SELECT 'C:\'`, 1},
+				{"SELECT 2", 1},
+			},
+		},
+		{
+			name:  "E string backslash escapes",
+			input: `SELECT E'it\'s; fine', e'\\';SELECT 2;`,
+			want: []scannedStatement{
+				{`SELECT E'it\'s; fine', e'\\'`, 1},
+				{"SELECT 2", 1},
+			},
+		},
+		{
+			name:  "identifier ending in e is not an E string",
+			input: `SELECT name'\';SELECT 2;`,
+			want: []scannedStatement{
+				{`SELECT name'\'`, 1},
+				{"SELECT 2", 1},
+			},
+		},
+		{
+			name:  "quoted identifier",
+			input: `SELECT "a;""b" FROM t;`,
+			want:  []scannedStatement{{`SELECT "a;""b" FROM t`, 1}},
+		},
+		{
+			name:  "anonymous dollar quote",
+			input: "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;\nSELECT 2;",
+			want: []scannedStatement{
+				{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql", 1},
+				{"SELECT 2", 2},
+			},
+		},
+		{
+			name:  "tagged dollar quote containing another tag",
+			input: "DO $body$\nBEGIN\n  PERFORM $$;$$;\nEND\n$body$;",
+			want:  []scannedStatement{{"DO $body$\nBEGIN\n  PERFORM $$;$$;\nEND\n$body$", 5}},
+		},
+		{
+			name:  "positional parameter is not a dollar quote",
+			input: "PREPARE p AS SELECT $1;SELECT 2;",
+			want: []scannedStatement{
+				{"PREPARE p AS SELECT $1", 1},
+				{"SELECT 2", 1},
+			},
+		},
+		{
+			name:  "line comments are dropped",
+			input: "-- header; not a statement\nSELECT 1; -- trailing;\nSELECT 2;",
+			want: []scannedStatement{
+				{"SELECT 1", 2},
+				{"SELECT 2", 3},
+			},
+		},
+		{
+			name:  "nested block comments are dropped",
+			input: "/* outer /* inner; */ still; comment */SELECT 1;\nSELECT /* ; */ 2;",
+			want: []scannedStatement{
+				{"SELECT 1", 1},
+				{"SELECT   2", 2},
+			},
+		},
+		{
+			name:  "comment markers inside strings are kept",
+			input: "SELECT '-- not a comment', '/* nor this';",
+			want:  []scannedStatement{{"SELECT '-- not a comment', '/* nor this'", 1}},
+		},
+		{
+			name:  "minus and division are operators",
+			input: "SELECT 4-2, 4/2;",
+			want:  []scannedStatement{{"SELECT 4-2, 4/2", 1}},
+		},
+		{
+			name:    "COPY FROM stdin is rejected",
+			input:   "SELECT 1;\nCOPY movies (id) FROM stdin;\n1\n\\.\n",
+			want:    []scannedStatement{{"SELECT 1", 1}},
+			wantErr: ErrCopyFromStdin,
+		},
+		{
+			name:  "COPY from a file is a statement",
+			input: "COPY movies FROM '/tmp/movies.csv';",
+			want:  []scannedStatement{{"COPY movies FROM '/tmp/movies.csv'", 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewStatementScanner(strings.NewReader(tt.input))
+			var got []scannedStatement
+			for scanner.Scan() {
+				got = append(got, scannedStatement{scanner.Statement(), scanner.Line()})
+			}
+
+			if !errors.Is(scanner.Err(), tt.wantErr) {
+				t.Errorf("Err() = %v, want %v", scanner.Err(), tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d statements %q, want %d %q", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("statement %d = %q on line %d, want %q on line %d",
+						i, got[i].text, got[i].line, tt.want[i].text, tt.want[i].line)
+				}
+			}
+		})
+	}
+}
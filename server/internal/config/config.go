@@ -0,0 +1,426 @@
+// Package config loads the service configuration once at startup. Values
+// come from built-in defaults, then an optional YAML file (CONFIG_FILE), then
+// environment variables, each layer overriding the previous one.
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+type Config struct {
+	Env       string         `yaml:"env"`
+	Server    ServerConfig   `yaml:"server"`
+	Database  DatabaseConfig `yaml:"database"`
+	Auth      AuthConfig     `yaml:"auth"`
+	Log       LogConfig      `yaml:"log"`
+	PublicDir string         `yaml:"public_dir"`
+	// PublicURL is the origin users and crawlers reach the site at, e.g.
+	// https://movies.example.com. Canonical links, structured data, the
+	// sitemaps, robots.txt and feed URLs are built from it rather than from
+	// the request's Host header. Left unset outside production, it points at
+	// Server.Addr on localhost.
+	PublicURL       string                `yaml:"public_url"`
+	Recommendations RecommendationsConfig `yaml:"recommendations"`
+	Metrics         MetricsConfig         `yaml:"metrics"`
+	Tracing         TracingConfig         `yaml:"tracing"`
+	HTTPCache       HTTPCacheConfig       `yaml:"http_cache"`
+	CatalogueCache  CatalogueCacheConfig  `yaml:"catalogue_cache"`
+	Compression     CompressionConfig     `yaml:"compression"`
+	Security        SecurityConfig        `yaml:"security"`
+	CORS            CORSConfig            `yaml:"cors"`
+	SSR             SSRConfig             `yaml:"ssr"`
+	Sitemap         SitemapConfig         `yaml:"sitemap"`
+}
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	// WriteTimeout also bounds streamed exports of large collections.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// ShutdownDrain is how long /health reports unhealthy before the
+	// listener is closed, giving load balancers time to stop routing here.
+	ShutdownDrain time.Duration `yaml:"shutdown_drain"`
+	// ShutdownTimeout bounds waiting for in-flight requests and background
+	// tasks once the listener is closed.
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout"`
+	MaxBodyBytes       int64         `yaml:"max_body_bytes"`
+	ImportMaxBodyBytes int64         `yaml:"import_max_body_bytes"`
+}
+
+type DatabaseConfig struct {
+	URL string `yaml:"url"`
+	// StatementTimeout is the server-side deadline for every SQL statement
+	// (Postgres statement_timeout); 0 leaves the server default.
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+	// AutoMigrate applies pending schema migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type AuthConfig struct {
+	JWTSecret string `yaml:"jwt_secret"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	// Outputs lists where records go: stdout, stderr and/or file (Path).
+	Outputs []string `yaml:"outputs"`
+	Path    string   `yaml:"path"`
+	// Redact masks emails and tokens in log records.
+	Redact bool `yaml:"redact"`
+}
+
+type RecommendationsConfig struct {
+	// FromFollows adds followed users' collections as a candidate source.
+	FromFollows bool `yaml:"from_follows"`
+	// RefreshTimeout bounds a background recommendation refresh, which
+	// outlives the request that triggered it.
+	RefreshTimeout time.Duration `yaml:"refresh_timeout"`
+}
+
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Path is where the Prometheus scrape endpoint is mounted.
+	Path string `yaml:"path"`
+}
+
+type TracingConfig struct {
+	// Enabled turns on span export; W3C trace-context propagation is
+	// always active.
+	Enabled bool `yaml:"enabled"`
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://otel-collector:4318.
+	Endpoint    string `yaml:"endpoint"`
+	ServiceName string `yaml:"service_name"`
+	// SampleRatio is the fraction of new traces recorded (0 to 1); sampled
+	// parents are always followed.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// HTTPCacheConfig sets the Cache-Control policy of each public, cacheable
+// route. Personalised routes are always private, no-store.
+type HTTPCacheConfig struct {
+	Movie        CachePolicy `yaml:"movie"`
+	Genres       CachePolicy `yaml:"genres"`
+	TopMovies    CachePolicy `yaml:"top_movies"`
+	RandomMovies CachePolicy `yaml:"random_movies"`
+	Search       CachePolicy `yaml:"search"`
+	// Pages covers the server-rendered HTML pages.
+	Pages CachePolicy `yaml:"pages"`
+	// Sitemaps covers /robots.txt, /sitemap.xml and the sitemaps it lists.
+	Sitemaps CachePolicy `yaml:"sitemaps"`
+}
+
+// CachePolicy is how long shared caches and browsers may reuse a response.
+// A zero MaxAge still allows storing it but requires revalidation (ETag).
+type CachePolicy struct {
+	MaxAge time.Duration `yaml:"max_age"`
+	// StaleWhileRevalidate lets caches serve a stale response for this long
+	// while they revalidate it in the background.
+	StaleWhileRevalidate time.Duration `yaml:"stale_while_revalidate"`
+}
+
+// CatalogueCacheConfig sizes the in-process cache of top movies, genres and
+// movie details kept in front of Postgres.
+type CatalogueCacheConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxEntries int  `yaml:"max_entries"`
+	// TTL bounds how stale a cached entry can get. Committed imports also
+	// clear the cache, through a Postgres notification, but one sent while
+	// the server's listener is reconnecting is only caught up with on
+	// reconnect.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type CompressionConfig struct {
+	// Enabled turns on gzip/brotli response compression.
+	Enabled bool `yaml:"enabled"`
+	// MinSize is the smallest body, in bytes, worth compressing.
+	MinSize int `yaml:"min_size"`
+}
+
+// SecurityConfig sets the browser security headers sent with every
+// response.
+type SecurityConfig struct {
+	// CSP is the Content-Security-Policy. Every {nonce} in it is replaced
+	// with a fresh nonce per request, which server-rendered inline scripts
+	// carry. Empty disables the header.
+	CSP string `yaml:"csp"`
+	// CSPReportOnly sends the policy as Content-Security-Policy-Report-Only,
+	// so violations are reported without being blocked.
+	CSPReportOnly bool `yaml:"csp_report_only"`
+	// HSTSMaxAge is sent in Strict-Transport-Security on HTTPS requests
+	// (directly or behind a proxy setting X-Forwarded-Proto). Zero disables
+	// the header.
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains"`
+	// PermissionsPolicy is sent as is; empty disables the header.
+	PermissionsPolicy string `yaml:"permissions_policy"`
+}
+
+// CORSConfig lets pages on other origins (the Vite dev server, partner
+// widgets) call the JSON API.
+type CORSConfig struct {
+	// AllowedOrigins lists origins such as https://partner.example, or "*"
+	// for any. Left unset, development allows the Vite dev server and
+	// other environments allow none.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// AllowCredentials lets those origins send cookies and read responses
+	// to credentialed requests. It cannot be combined with "*".
+	AllowCredentials bool `yaml:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// SSRConfig controls the server-rendered pages.
+type SSRConfig struct {
+	// Reload re-parses index.html and the page templates whenever they
+	// change on disk, rather than only at startup. Meant for development.
+	Reload bool `yaml:"reload"`
+	// TemplateDir reads the page templates from this directory instead of
+	// the copy built into the binary. With Reload and no TemplateDir, the
+	// templates are read from the source tree when it is found.
+	TemplateDir string `yaml:"template_dir"`
+}
+
+// MaxSitemapURLs is the most URLs the sitemap protocol allows in one file.
+const MaxSitemapURLs = 50000
+
+// SitemapConfig controls /sitemap.xml and the sitemaps it lists.
+type SitemapConfig struct {
+	// URLsPerFile is how many movies each movie sitemap lists, at most
+	// MaxSitemapURLs.
+	URLsPerFile int `yaml:"urls_per_file"`
+	// CacheTTL is how long a generated sitemap is served from memory before
+	// it is streamed from Postgres again. Committed imports clear it sooner.
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// viteDevOrigin is where `npm run dev` serves the frontend.
+const viteDevOrigin = "http://localhost:5173"
+
+// defaultCSP allows the app's own scripts plus the inline scripts carrying
+// the request nonce, Google Fonts, TMDB posters and YouTube trailers.
+// Inline styles stay allowed: the markup and the components set them.
+const defaultCSP = "default-src 'self'; " +
+	"script-src 'self' 'nonce-{nonce}'; " +
+	"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+	"font-src 'self' https://fonts.gstatic.com; " +
+	"img-src 'self' data: https:; " +
+	"frame-src https://www.youtube.com https://www.youtube-nocookie.com; " +
+	"connect-src 'self' https://fonts.googleapis.com https://fonts.gstatic.com; " +
+	"object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'none'"
+
+func defaults() Config {
+	return Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Addr:               ":8080",
+			ReadHeaderTimeout:  5 * time.Second,
+			ReadTimeout:        30 * time.Second,
+			WriteTimeout:       60 * time.Second,
+			IdleTimeout:        120 * time.Second,
+			ShutdownDrain:      5 * time.Second,
+			ShutdownTimeout:    20 * time.Second,
+			MaxBodyBytes:       1 << 20,  // 1 MB
+			ImportMaxBodyBytes: 16 << 20, // CSV exports of long histories
+		},
+		Database: DatabaseConfig{
+			StatementTimeout: 10 * time.Second,
+		},
+		Log: LogConfig{
+			Level:   "info",
+			Format:  logger.FormatJSON,
+			Outputs: []string{logger.OutputStdout, logger.OutputFile},
+			Redact:  true,
+		},
+		Recommendations: RecommendationsConfig{
+			RefreshTimeout: 2 * time.Minute,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "movies-api",
+			SampleRatio: 1,
+		},
+		HTTPCache: HTTPCacheConfig{
+			Movie:     CachePolicy{MaxAge: 5 * time.Minute, StaleWhileRevalidate: time.Hour},
+			Genres:    CachePolicy{MaxAge: time.Hour, StaleWhileRevalidate: 24 * time.Hour},
+			TopMovies: CachePolicy{MaxAge: 5 * time.Minute, StaleWhileRevalidate: time.Hour},
+			Search:    CachePolicy{MaxAge: time.Minute, StaleWhileRevalidate: 5 * time.Minute},
+			Sitemaps:  CachePolicy{MaxAge: time.Hour, StaleWhileRevalidate: 24 * time.Hour},
+			// Random picks and pages embedding them change on every request
+		},
+		CatalogueCache: CatalogueCacheConfig{
+			Enabled:    true,
+			MaxEntries: 10000,
+			TTL:        5 * time.Minute,
+		},
+		Compression: CompressionConfig{
+			Enabled: true,
+			MinSize: 1024,
+		},
+		Security: SecurityConfig{
+			CSP:                   defaultCSP,
+			HSTSMaxAge:            365 * 24 * time.Hour,
+			HSTSIncludeSubdomains: true,
+			PermissionsPolicy:     "camera=(), microphone=(), geolocation=(), payment=(), usb=()",
+		},
+		CORS: CORSConfig{
+			MaxAge: 2 * time.Hour, // the longest Chromium honours
+		},
+		Sitemap: SitemapConfig{
+			URLsPerFile: MaxSitemapURLs,
+			CacheTTL:    time.Hour,
+		},
+	}
+}
+
+// Load reads .env (if present), the optional YAML file named by CONFIG_FILE
+// and the environment, fills in derived defaults and validates the result.
+// A *ValidationError lists every problem found, not just the first.
+func Load() (*Config, error) {
+	loadDotEnv()
+
+	cfg := defaults()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []string
+	applyEnv(&cfg, &problems)
+	cfg.resolvePaths()
+	cfg.resolvePublicURL()
+	cfg.resolveCORS()
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &cfg, nil
+}
+
+// IsProduction reports whether the service runs with APP_ENV=production.
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// loadDotEnv loads the first .env found walking up from the working
+// directory, so the server runs the same from the repo root, server/ or
+// server/cmd/api. Variables already set in the environment win.
+func loadDotEnv() {
+	for _, path := range []string{".env", "../.env", "../../.env"} {
+		if err := godotenv.Load(path); err == nil {
+			log.Printf("Loaded .env from: %s", path)
+			return
+		}
+	}
+	log.Printf("No .env file found in any of the expected locations. Using environment variables only.")
+}
+
+func loadFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// resolvePaths fills in the public directory, the template directory and
+// the log file when they were not configured, using the same fallbacks the
+// server has always used.
+func (c *Config) resolvePaths() {
+	if c.PublicDir == "" {
+		// Project root first, then relative to server/cmd/api (development)
+		c.PublicDir = "public"
+		if _, err := os.Stat(c.PublicDir); os.IsNotExist(err) {
+			c.PublicDir = "../../public"
+		}
+	}
+	if abs, err := filepath.Abs(c.PublicDir); err == nil {
+		c.PublicDir = abs
+	}
+
+	if c.SSR.Reload && c.SSR.TemplateDir == "" {
+		// Edit the templates in place: from server/, the repo root or
+		// server/cmd/api
+		for _, dir := range []string{"internal/ssr/templates", "server/internal/ssr/templates", "../../internal/ssr/templates"} {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				c.SSR.TemplateDir = dir
+				break
+			}
+		}
+	}
+	if c.SSR.TemplateDir != "" {
+		if abs, err := filepath.Abs(c.SSR.TemplateDir); err == nil {
+			c.SSR.TemplateDir = abs
+		}
+	}
+
+	if c.Log.Path == "" {
+		// /app/logs is a tmpfs in production (read-only root filesystem)
+		if _, err := os.Stat("/app/logs"); err == nil {
+			c.Log.Path = "/app/logs/movie-service.log"
+		} else if _, err := os.Stat("/tmp"); err == nil {
+			c.Log.Path = "/tmp/movie-service.log"
+		} else {
+			c.Log.Path = "movie-service.log"
+		}
+	}
+}
+
+// resolvePublicURL drops a trailing slash from the public URL and, outside
+// production, defaults it to the server's own address.
+func (c *Config) resolvePublicURL() {
+	c.PublicURL = strings.TrimSuffix(c.PublicURL, "/")
+	if c.PublicURL != "" || c.IsProduction() {
+		return
+	}
+	host, port, err := net.SplitHostPort(c.Server.Addr)
+	if err != nil {
+		return // reported by validate
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	c.PublicURL = "http://" + net.JoinHostPort(host, port)
+}
+
+// resolveCORS lets the Vite dev server call the API in development when no
+// origins were configured.
+func (c *Config) resolveCORS() {
+	if c.CORS.AllowedOrigins == nil && c.Env == EnvDevelopment {
+		c.CORS.AllowedOrigins = []string{viteDevOrigin}
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv overrides cfg with the environment variables that are set.
+// Values that cannot be parsed are reported in problems and leave the
+// previous value in place.
+func applyEnv(cfg *Config, problems *[]string) {
+	envString("APP_ENV", &cfg.Env)
+
+	envString("HTTP_ADDR", &cfg.Server.Addr)
+	envDuration("HTTP_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout, problems)
+	envDuration("HTTP_READ_TIMEOUT", &cfg.Server.ReadTimeout, problems)
+	envDuration("HTTP_WRITE_TIMEOUT", &cfg.Server.WriteTimeout, problems)
+	envDuration("HTTP_IDLE_TIMEOUT", &cfg.Server.IdleTimeout, problems)
+	envDuration("SHUTDOWN_DRAIN_PERIOD", &cfg.Server.ShutdownDrain, problems)
+	envDuration("SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout, problems)
+	envInt64("HTTP_MAX_BODY_BYTES", &cfg.Server.MaxBodyBytes, problems)
+	envInt64("HTTP_IMPORT_MAX_BODY_BYTES", &cfg.Server.ImportMaxBodyBytes, problems)
+
+	envString("DATABASE_URL", &cfg.Database.URL)
+	envDuration("DB_STATEMENT_TIMEOUT", &cfg.Database.StatementTimeout, problems)
+	envBool("DB_AUTO_MIGRATE", &cfg.Database.AutoMigrate, problems)
+	envString("JWT_SECRET", &cfg.Auth.JWTSecret)
+	envString("LOG_LEVEL", &cfg.Log.Level)
+	envString("LOG_FORMAT", &cfg.Log.Format)
+	envList("LOG_OUTPUTS", &cfg.Log.Outputs)
+	envString("LOG_PATH", &cfg.Log.Path)
+	envBool("LOG_REDACT", &cfg.Log.Redact, problems)
+	envString("PUBLIC_DIR", &cfg.PublicDir)
+	envString("PUBLIC_URL", &cfg.PublicURL)
+
+	envBool("RECOMMEND_FROM_FOLLOWS", &cfg.Recommendations.FromFollows, problems)
+	envDuration("RECOMMENDATION_REFRESH_TIMEOUT", &cfg.Recommendations.RefreshTimeout, problems)
+
+	envBool("METRICS_ENABLED", &cfg.Metrics.Enabled, problems)
+	envString("METRICS_PATH", &cfg.Metrics.Path)
+
+	envBool("TRACING_ENABLED", &cfg.Tracing.Enabled, problems)
+	envString("OTEL_EXPORTER_OTLP_ENDPOINT", &cfg.Tracing.Endpoint)
+	envString("OTEL_SERVICE_NAME", &cfg.Tracing.ServiceName)
+	envFloat("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio, problems)
+
+	envCachePolicy("CACHE_MOVIE", &cfg.HTTPCache.Movie, problems)
+	envCachePolicy("CACHE_GENRES", &cfg.HTTPCache.Genres, problems)
+	envCachePolicy("CACHE_TOP_MOVIES", &cfg.HTTPCache.TopMovies, problems)
+	envCachePolicy("CACHE_RANDOM_MOVIES", &cfg.HTTPCache.RandomMovies, problems)
+	envCachePolicy("CACHE_SEARCH", &cfg.HTTPCache.Search, problems)
+	envCachePolicy("CACHE_PAGES", &cfg.HTTPCache.Pages, problems)
+	envCachePolicy("CACHE_SITEMAPS", &cfg.HTTPCache.Sitemaps, problems)
+
+	envBool("CATALOGUE_CACHE_ENABLED", &cfg.CatalogueCache.Enabled, problems)
+	envInt("CATALOGUE_CACHE_MAX_ENTRIES", &cfg.CatalogueCache.MaxEntries, problems)
+	envDuration("CATALOGUE_CACHE_TTL", &cfg.CatalogueCache.TTL, problems)
+
+	envBool("COMPRESSION_ENABLED", &cfg.Compression.Enabled, problems)
+	envInt("COMPRESSION_MIN_SIZE", &cfg.Compression.MinSize, problems)
+
+	envString("CONTENT_SECURITY_POLICY", &cfg.Security.CSP)
+	envBool("CONTENT_SECURITY_POLICY_REPORT_ONLY", &cfg.Security.CSPReportOnly, problems)
+	envDuration("HSTS_MAX_AGE", &cfg.Security.HSTSMaxAge, problems)
+	envBool("HSTS_INCLUDE_SUBDOMAINS", &cfg.Security.HSTSIncludeSubdomains, problems)
+	envString("PERMISSIONS_POLICY", &cfg.Security.PermissionsPolicy)
+
+	envList("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	envBool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials, problems)
+	envDuration("CORS_MAX_AGE", &cfg.CORS.MaxAge, problems)
+
+	envBool("SSR_RELOAD", &cfg.SSR.Reload, problems)
+	envString("SSR_TEMPLATE_DIR", &cfg.SSR.TemplateDir)
+
+	envInt("SITEMAP_URLS_PER_FILE", &cfg.Sitemap.URLsPerFile, problems)
+	envDuration("SITEMAP_CACHE_TTL", &cfg.Sitemap.CacheTTL, problems)
+}
+
+// envCachePolicy reads <prefix>_MAX_AGE and <prefix>_STALE_WHILE_REVALIDATE.
+func envCachePolicy(prefix string, dst *CachePolicy, problems *[]string) {
+	envDuration(prefix+"_MAX_AGE", &dst.MaxAge, problems)
+	envDuration(prefix+"_STALE_WHILE_REVALIDATE", &dst.StaleWhileRevalidate, problems)
+}
+
+func envString(name string, dst *string) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		*dst = value
+	}
+}
+
+// envList reads a comma-separated list, ignoring blank items.
+func envList(name string, dst *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func envDuration(name string, dst *time.Duration, problems *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %q is not a duration (e.g. 30s, 2m)", name, value))
+		return
+	}
+	*dst = d
+}
+
+func envInt64(name string, dst *int64, problems *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %q is not an integer", name, value))
+		return
+	}
+	*dst = n
+}
+
+func envInt(name string, dst *int, problems *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %q is not an integer", name, value))
+		return
+	}
+	*dst = n
+}
+
+func envFloat(name string, dst *float64, problems *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %q is not a number", name, value))
+		return
+	}
+	*dst = f
+}
+
+func envBool(name string, dst *bool, problems *[]string) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %q is not a boolean", name, value))
+		return
+	}
+	*dst = b
+}
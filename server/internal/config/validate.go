@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// minProductionSecretLength guards against placeholder JWT secrets such as
+// the one in .env.example reaching production.
+const minProductionSecretLength = 32
+
+// ValidationError reports every configuration problem found at startup.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d problems):", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvProduction, EnvTest:
+	default:
+		add("APP_ENV: %q must be one of %s, %s, %s", c.Env, EnvDevelopment, EnvProduction, EnvTest)
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		add("HTTP_ADDR: %q is not a host:port address", c.Server.Addr)
+	}
+
+	positive := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"RECOMMENDATION_REFRESH_TIMEOUT", c.Recommendations.RefreshTimeout},
+	}
+	for _, t := range positive {
+		if t.value <= 0 {
+			add("%s: must be greater than zero", t.name)
+		}
+	}
+	if c.Server.ShutdownDrain < 0 {
+		add("SHUTDOWN_DRAIN_PERIOD: must not be negative")
+	}
+
+	if c.Server.MaxBodyBytes <= 0 {
+		add("HTTP_MAX_BODY_BYTES: must be greater than zero")
+	}
+	if c.Server.ImportMaxBodyBytes < c.Server.MaxBodyBytes {
+		add("HTTP_IMPORT_MAX_BODY_BYTES: must be at least HTTP_MAX_BODY_BYTES (%d)", c.Server.MaxBodyBytes)
+	}
+
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		add("LOG_LEVEL: %q must be one of debug, info, warn, error", c.Log.Level)
+	}
+	if c.Log.Format != logger.FormatJSON && c.Log.Format != logger.FormatText {
+		add("LOG_FORMAT: %q must be %s or %s", c.Log.Format, logger.FormatJSON, logger.FormatText)
+	}
+	for _, output := range c.Log.Outputs {
+		switch output {
+		case logger.OutputStdout, logger.OutputStderr, logger.OutputFile:
+		default:
+			add("LOG_OUTPUTS: unknown output %q (use stdout, stderr, file)", output)
+		}
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		add("METRICS_PATH: %q must start with /", c.Metrics.Path)
+	}
+
+	if c.Tracing.Enabled {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			add("OTEL_EXPORTER_OTLP_ENDPOINT: %q is not an absolute URL", c.Tracing.Endpoint)
+		}
+		if c.Tracing.ServiceName == "" {
+			add("OTEL_SERVICE_NAME: is required when tracing is enabled")
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("TRACING_SAMPLE_RATIO: %v must be between 0 and 1", c.Tracing.SampleRatio)
+	}
+
+	caches := []struct {
+		prefix string
+		policy CachePolicy
+	}{
+		{"CACHE_MOVIE", c.HTTPCache.Movie},
+		{"CACHE_GENRES", c.HTTPCache.Genres},
+		{"CACHE_TOP_MOVIES", c.HTTPCache.TopMovies},
+		{"CACHE_RANDOM_MOVIES", c.HTTPCache.RandomMovies},
+		{"CACHE_SEARCH", c.HTTPCache.Search},
+		{"CACHE_PAGES", c.HTTPCache.Pages},
+		{"CACHE_SITEMAPS", c.HTTPCache.Sitemaps},
+	}
+	for _, cache := range caches {
+		if cache.policy.MaxAge < 0 {
+			add("%s_MAX_AGE: must not be negative", cache.prefix)
+		}
+		if cache.policy.StaleWhileRevalidate < 0 {
+			add("%s_STALE_WHILE_REVALIDATE: must not be negative", cache.prefix)
+		}
+	}
+
+	if c.CatalogueCache.Enabled {
+		if c.CatalogueCache.MaxEntries <= 0 {
+			add("CATALOGUE_CACHE_MAX_ENTRIES: must be greater than zero")
+		}
+		if c.CatalogueCache.TTL <= 0 {
+			add("CATALOGUE_CACHE_TTL: must be greater than zero")
+		}
+	}
+
+	if c.Compression.MinSize < 0 {
+		add("COMPRESSION_MIN_SIZE: must not be negative")
+	}
+
+	if c.Security.HSTSMaxAge < 0 {
+		add("HSTS_MAX_AGE: must not be negative (0 disables it)")
+	}
+	if c.CORS.MaxAge < 0 {
+		add("CORS_MAX_AGE: must not be negative")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		switch {
+		case origin == "*":
+			if c.CORS.AllowCredentials {
+				add("CORS_ALLOWED_ORIGINS: \"*\" cannot be combined with CORS_ALLOW_CREDENTIALS")
+			}
+		case !isOrigin(origin):
+			add("CORS_ALLOWED_ORIGINS: %q is not an origin such as https://example.com", origin)
+		}
+	}
+
+	switch {
+	case c.PublicURL == "" && c.IsProduction():
+		add("PUBLIC_URL: is required in production, e.g. https://movies.example.com")
+	case c.PublicURL != "" && !isOrigin(c.PublicURL):
+		add("PUBLIC_URL: %q is not an origin such as https://movies.example.com", c.PublicURL)
+	}
+
+	if c.SSR.TemplateDir != "" {
+		if info, err := os.Stat(c.SSR.TemplateDir); err != nil || !info.IsDir() {
+			add("SSR_TEMPLATE_DIR: %q is not a directory", c.SSR.TemplateDir)
+		}
+	}
+
+	if c.Sitemap.URLsPerFile <= 0 || c.Sitemap.URLsPerFile > MaxSitemapURLs {
+		add("SITEMAP_URLS_PER_FILE: %d must be between 1 and %d", c.Sitemap.URLsPerFile, MaxSitemapURLs)
+	}
+	if c.Sitemap.CacheTTL <= 0 {
+		add("SITEMAP_CACHE_TTL: must be greater than zero")
+	}
+
+	if c.Database.URL == "" {
+		add("DATABASE_URL: is required")
+	}
+	if c.Database.StatementTimeout < 0 {
+		add("DB_STATEMENT_TIMEOUT: must not be negative (0 disables it)")
+	}
+
+	switch {
+	case c.Auth.JWTSecret == "":
+		add("JWT_SECRET: is required; set it to a strong, unique random value")
+	case c.IsProduction() && len(c.Auth.JWTSecret) < minProductionSecretLength:
+		add("JWT_SECRET: must be at least %d characters in production", minProductionSecretLength)
+	}
+
+	return problems
+}
+
+// isOrigin reports whether s is a bare scheme://host[:port], the form
+// browsers send in the Origin header.
+func isOrigin(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
@@ -0,0 +1,29 @@
+package entity
+
+// Activity kinds are recorded when a user adds a movie to a collection.
+// The app has no ratings or reviews yet; kinds for them belong here once
+// something writes them.
+const (
+	ActivityFavorited   = "favorited"
+	ActivityWatchlisted = "watchlisted"
+)
+
+func IsValidActivityType(eventType string) bool {
+	switch eventType {
+	case ActivityFavorited, ActivityWatchlisted:
+		return true
+	default:
+		return false
+	}
+}
+
+func ActivityTypeForCollection(collectionType string) string {
+	switch collectionType {
+	case CollectionFavorites:
+		return ActivityFavorited
+	case CollectionWatchlist:
+		return ActivityWatchlisted
+	default:
+		return ""
+	}
+}
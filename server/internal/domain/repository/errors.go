@@ -8,6 +8,11 @@ var (
 	ErrUserNotFound  = errors.New("user not found")
 )
 
+// Movie errors
+var (
+	ErrInvalidMovieID = errors.New("movie ID must be a positive integer")
+)
+
 // Domain errors (business rules)
 var (
 	ErrUserAlreadyExists        = errors.New("user already exists")
@@ -25,8 +30,41 @@ var (
 	ErrInvalidCollectionType   = errors.New("invalid collection type")
 )
 
+// Search errors
+var (
+	ErrSearchQueryRequired  = errors.New("search query is required")
+	ErrInvalidMovieRelation = errors.New("include must list genres, cast or keywords")
+)
+
 // Recommendation errors
 var (
 	ErrRecommendationsNotFound = errors.New("recommendations not found")
 	ErrEmbeddingNotFound       = errors.New("embedding not found")
 )
+
+// Social errors
+var (
+	ErrCannotFollowSelf    = errors.New("users cannot follow themselves")
+	ErrInvalidActivityType = errors.New("invalid activity type")
+)
+
+// Import errors
+var (
+	ErrTooManyImportRows = errors.New("import exceeds the maximum number of rows")
+)
+
+// Feed errors
+var (
+	ErrFeedTokenNotFound = errors.New("feed token not found")
+	ErrInvalidFeedKind   = errors.New("invalid feed kind")
+)
+
+// Stats errors
+var (
+	ErrInvalidStatsYear = errors.New("invalid stats year")
+)
+
+// Sitemap errors
+var (
+	ErrSitemapNotFound = errors.New("sitemap not found")
+)
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+type FeedRepository interface {
+	GetUserIDByEmail(ctx context.Context, email string) (int, error)
+	SaveFeedToken(ctx context.Context, userID int, tokenHash string) error
+	RevokeFeedToken(ctx context.Context, userID int) (bool, error)
+	GetUserIDByFeedToken(ctx context.Context, tokenHash string) (int, error)
+	GetCollectionVersion(ctx context.Context, userID int, collectionType string) (time.Time, int, error)
+	GetCollectionEntries(ctx context.Context, userID int, collectionType string, limit int) ([]models.CollectionEntry, error)
+	GetRecommendationsVersion(ctx context.Context, userID int) (time.Time, int, error)
+	GetRecommendationEntries(ctx context.Context, userID int, limit int) ([]models.CollectionEntry, error)
+}
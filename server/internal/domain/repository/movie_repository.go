@@ -1,11 +1,48 @@
 package repository
 
-import "github.com/jgamaraalv/movies.git/models"
+import (
+	"context"
+	"slices"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+// MovieRelation is data related to a movie that list queries leave out
+// unless it is asked for.
+type MovieRelation string
+
+const (
+	RelationGenres   MovieRelation = "genres"
+	RelationCast     MovieRelation = "cast"
+	RelationKeywords MovieRelation = "keywords"
+)
+
+// AllMovieRelations are loaded for a single movie's details.
+var AllMovieRelations = []MovieRelation{RelationGenres, RelationCast, RelationKeywords}
+
+// ValidateMovieRelations rejects relations that do not exist.
+func ValidateMovieRelations(relations []MovieRelation) error {
+	for _, relation := range relations {
+		if !slices.Contains(AllMovieRelations, relation) {
+			return ErrInvalidMovieRelation
+		}
+	}
+	return nil
+}
 
 type MovieRepository interface {
-	GetTopMovies() ([]models.Movie, error)
-	GetRandomMovies() ([]models.Movie, error)
-	GetMovieByID(id int) (models.Movie, error)
-	SearchMoviesByName(query string, orderBy string, genreID *int) ([]models.Movie, error)
-	GetAllGenres() ([]models.Genre, error)
+	GetTopMovies(ctx context.Context) ([]models.Movie, error)
+	GetRandomMovies(ctx context.Context) ([]models.Movie, error)
+	GetMovieByID(ctx context.Context, id int) (models.Movie, error)
+	SearchMoviesByName(ctx context.Context, query string, orderBy string, genreID *int) ([]models.Movie, error)
+	GetAllGenres(ctx context.Context) ([]models.Genre, error)
+	GetMoviesByTMDBIDs(ctx context.Context, tmdbIDs []int) ([]models.Movie, error)
+	GetMoviesByReleaseYear(ctx context.Context, year int) ([]models.Movie, error)
+	FindMoviesByTitle(ctx context.Context, title string) ([]models.Movie, error)
+	// FindMoviesByTitleWords returns up to limit movies, most popular first,
+	// whose title contains any of words, as candidates for fuzzy matching.
+	FindMoviesByTitleWords(ctx context.Context, words []string, limit int) ([]models.Movie, error)
+	// LoadRelations fills in the given relations of every movie in place,
+	// with one query per relation whatever the number of movies.
+	LoadRelations(ctx context.Context, movies []models.Movie, relations []MovieRelation) error
 }
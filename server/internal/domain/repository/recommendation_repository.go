@@ -1,12 +1,16 @@
 package repository
 
-import "github.com/jgamaraalv/movies.git/models"
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
 
 type RecommendationRepository interface {
-	GetRecommendations(userID int, limit int) ([]models.Movie, error)
-	HasRecommendations(userID int) (bool, error)
-	GetUserIDByEmail(email string) (int, error)
-	InvalidateRecommendations(userID int) error
-	RecomputeUserEmbedding(userID int) error
-	ComputeRecommendations(userID int) error
+	GetRecommendations(ctx context.Context, userID int, limit int) ([]models.Movie, error)
+	HasRecommendations(ctx context.Context, userID int) (bool, error)
+	GetUserIDByEmail(ctx context.Context, email string) (int, error)
+	InvalidateRecommendations(ctx context.Context, userID int) error
+	RecomputeUserEmbedding(ctx context.Context, userID int) error
+	ComputeRecommendations(ctx context.Context, userID int) error
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+// SitemapRepository lists the public catalogue pages for search engines.
+// Movies are split, in ID order, into pages of at most size entries, so a
+// page keeps its contents until movies are added or removed before it.
+type SitemapRepository interface {
+	// GetMoviePages returns, for each page of movies, when its latest movie
+	// changed.
+	GetMoviePages(ctx context.Context, size int) ([]time.Time, error)
+	// StreamMoviePage calls fn for each movie on the zero-based page as rows
+	// are read. Iteration stops at the first error returned by fn.
+	StreamMoviePage(ctx context.Context, page int, size int, fn func(models.SitemapEntry) error) error
+	// GetGenres returns every genre with when its latest movie changed.
+	GetGenres(ctx context.Context) ([]models.SitemapEntry, error)
+}
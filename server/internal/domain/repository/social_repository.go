@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+type SocialRepository interface {
+	GetUserIDByEmail(ctx context.Context, email string) (int, error)
+	Follow(ctx context.Context, followerID int, followeeID int) (bool, error)
+	Unfollow(ctx context.Context, followerID int, followeeID int) (bool, error)
+	GetFollowers(ctx context.Context, userID int, limit int, offset int) ([]models.UserSummary, error)
+	GetFollowing(ctx context.Context, userID int, limit int, offset int) ([]models.UserSummary, error)
+	RecordActivity(ctx context.Context, event models.ActivityEvent) error
+	GetFeed(ctx context.Context, userID int, beforeID int64, limit int) ([]models.ActivityEvent, error)
+}
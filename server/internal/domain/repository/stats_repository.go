@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+// StatsRepository aggregates a user's collections. A year of 0 covers the
+// whole history; otherwise only movies added during that year are counted.
+type StatsRepository interface {
+	GetUserIDByEmail(ctx context.Context, email string) (int, error)
+	GetCollectionTotals(ctx context.Context, userID int, year int) (models.CollectionTotals, error)
+	GetGenreDistribution(ctx context.Context, userID int, year int) ([]models.GenreStat, error)
+	GetDecadeDistribution(ctx context.Context, userID int, year int) ([]models.DecadeStat, error)
+	GetTopActors(ctx context.Context, userID int, year int, limit int) ([]models.ActorStat, error)
+	GetScoreComparison(ctx context.Context, userID int, year int) (models.ScoreComparison, error)
+	GetCollectionGrowth(ctx context.Context, userID int, year int) ([]models.GrowthPoint, error)
+}
@@ -1,10 +1,16 @@
 package repository
 
-import "github.com/jgamaraalv/movies.git/models"
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
 
 type UserRepository interface {
-	Register(name string, email string, hashedPassword string) (bool, error)
-	Authenticate(email string, password string) (bool, error)
-	GetAccountDetails(email string) (models.User, error)
-	SaveCollection(user models.User, movieID int, collectionType string) (bool, error)
+	Register(ctx context.Context, name string, email string, hashedPassword string) (bool, error)
+	Authenticate(ctx context.Context, email string, password string) (bool, error)
+	GetAccountDetails(ctx context.Context, email string) (models.User, error)
+	SaveCollection(ctx context.Context, user models.User, movieID int, collectionType string) (bool, error)
+	SaveCollectionItems(ctx context.Context, email string, items []models.CollectionItem) (int, error)
+	StreamCollection(ctx context.Context, email string, collectionType string, fn func(models.CollectionEntry) error) error
 }
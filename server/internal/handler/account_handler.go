@@ -5,13 +5,20 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/domain/entity"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/infrastructure/csvimport"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
 	accountuc "github.com/jgamaraalv/movies.git/internal/usecase/account"
 	movieuc "github.com/jgamaraalv/movies.git/internal/usecase/movie"
+	socialuc "github.com/jgamaraalv/movies.git/internal/usecase/social"
+	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
-	"github.com/jgamaraalv/movies.git/pkg/token"
 )
 
 type RegisterRequest struct {
@@ -30,6 +37,10 @@ type CollectionRequest struct {
 	Collection string `json:"collection"`
 }
 
+// importMaxMemory is how much of an import upload is kept in memory before
+// spilling to temporary files.
+const importMaxMemory = 8 << 20
+
 type AuthResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
@@ -43,30 +54,96 @@ type contextKey string
 const emailContextKey contextKey = "email"
 
 type AccountHandler struct {
-	registerUC                   *accountuc.RegisterUseCase
-	authenticateUC               *accountuc.AuthenticateUseCase
-	getFavoritesUC               *accountuc.GetFavoritesUseCase
-	getWatchlistUC               *accountuc.GetWatchlistUseCase
-	saveToCollectionUC           *accountuc.SaveToCollectionUseCase
-	updateUserRecommendationsUC  *movieuc.UpdateUserRecommendationsUseCase
-	logger                       *logger.Logger
+	registerUC                  *accountuc.RegisterUseCase
+	authenticateUC              *accountuc.AuthenticateUseCase
+	getFavoritesUC              *accountuc.GetFavoritesUseCase
+	getWatchlistUC              *accountuc.GetWatchlistUseCase
+	saveToCollectionUC          *accountuc.SaveToCollectionUseCase
+	importCollectionUC          *accountuc.ImportCollectionUseCase
+	exportCollectionUC          *accountuc.ExportCollectionUseCase
+	updateUserRecommendationsUC *movieuc.UpdateUserRecommendationsUseCase
+	recordActivityUC            *socialuc.RecordActivityUseCase
+	jwtSecret                   []byte
+	backgroundTimeout           time.Duration
+	metrics                     *metrics.Metrics
+	logger                      *logger.Logger
+
+	// background tracks fire-and-forget recommendation refreshes so shutdown
+	// can wait for them instead of cutting them off mid-write.
+	background   sync.WaitGroup
+	backgroundMu sync.Mutex
+	draining     bool
 }
 
-func NewAccountHandler(repo repository.UserRepository, recRepo repository.RecommendationRepository, log *logger.Logger) *AccountHandler {
+func NewAccountHandler(repo repository.UserRepository, movieRepo repository.MovieRepository, recRepo repository.RecommendationRepository, socialRepo repository.SocialRepository, authCfg config.AuthConfig, recCfg config.RecommendationsConfig, m *metrics.Metrics, log *logger.Logger) *AccountHandler {
 	h := &AccountHandler{
-		registerUC:         accountuc.NewRegisterUseCase(repo, log),
-		authenticateUC:     accountuc.NewAuthenticateUseCase(repo, log),
-		getFavoritesUC:     accountuc.NewGetFavoritesUseCase(repo, log),
-		getWatchlistUC:     accountuc.NewGetWatchlistUseCase(repo, log),
+		registerUC:         accountuc.NewRegisterUseCase(repo, authCfg.JWTSecret, log),
+		authenticateUC:     accountuc.NewAuthenticateUseCase(repo, authCfg.JWTSecret, log),
+		getFavoritesUC:     accountuc.NewGetFavoritesUseCase(repo, movieRepo, log),
+		getWatchlistUC:     accountuc.NewGetWatchlistUseCase(repo, movieRepo, log),
 		saveToCollectionUC: accountuc.NewSaveToCollectionUseCase(repo, log),
+		importCollectionUC: accountuc.NewImportCollectionUseCase(repo, movieRepo, log),
+		exportCollectionUC: accountuc.NewExportCollectionUseCase(repo, log),
+		jwtSecret:          []byte(authCfg.JWTSecret),
+		backgroundTimeout:  recCfg.RefreshTimeout,
+		metrics:            m,
 		logger:             log,
 	}
 	if recRepo != nil {
 		h.updateUserRecommendationsUC = movieuc.NewUpdateUserRecommendationsUseCase(recRepo, log)
 	}
+	if socialRepo != nil {
+		h.recordActivityUC = socialuc.NewRecordActivityUseCase(socialRepo, log)
+	}
 	return h
 }
 
+// runInBackground starts job in its own goroutine unless the handler is
+// draining for shutdown, in which case the task is dropped; recommendations
+// are recomputed on the user's next collection change anyway. Failures are
+// logged and counted under the job name. fn receives ctx detached from the
+// request's cancellation, so the job outlives the response but keeps its
+// trace, and bounded by the handler's background timeout.
+func (h *AccountHandler) runInBackground(ctx context.Context, job string, fn func(context.Context) error) {
+	h.backgroundMu.Lock()
+	defer h.backgroundMu.Unlock()
+	if h.draining {
+		h.logger.Info("Skipping background task: server is shutting down")
+		return
+	}
+	h.background.Add(1)
+	go func() {
+		defer h.background.Done()
+		ctx, cancel := context.WithTimeout(ctx, h.backgroundTimeout)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			h.logger.Error("Background job failed", err, "job", job)
+			h.metrics.BackgroundJobFailed(job)
+		}
+	}()
+}
+
+// DrainBackground stops accepting background tasks and waits for the running
+// ones to finish, or until ctx is done.
+func (h *AccountHandler) DrainBackground(ctx context.Context) error {
+	h.backgroundMu.Lock()
+	h.draining = true
+	h.backgroundMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (h *AccountHandler) writeJSONResponse(w http.ResponseWriter, data interface{}) error {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -77,33 +154,22 @@ func (h *AccountHandler) writeJSONResponse(w http.ResponseWriter, data interface
 	return nil
 }
 
-func (h *AccountHandler) handleError(w http.ResponseWriter, err error) bool {
-	if err != nil {
-		switch err {
-		case repository.ErrAuthenticationValidation, repository.ErrUserAlreadyExists, repository.ErrRegistrationValidation:
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(AuthResponse{Success: false, Message: err.Error()})
-			return true
-		case repository.ErrUserNotFound:
-			http.Error(w, "User not found", http.StatusNotFound)
-			return true
-		default:
-			h.logger.Error("Handler error", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(AuthResponse{Success: false, Message: "An unexpected error occurred"})
-			return true
-		}
+func (h *AccountHandler) handleError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err == nil {
+		return false
 	}
-	return false
+	writeError(w, r, h.logger, err)
+	return true
 }
 
 func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
+	// The response carries a session token
+	w.Header().Set("Cache-Control", CacheControlPrivate)
+
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("Failed to decode registration request", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		requestLogger(r, h.logger).Error("Failed to decode registration request", err)
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "Invalid request body")
 		return
 	}
 
@@ -113,8 +179,8 @@ func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Password: req.Password,
 	}
 
-	output, err := h.registerUC.Execute(input)
-	if h.handleError(w, err) {
+	output, err := h.registerUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 
@@ -127,10 +193,12 @@ func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AccountHandler) Authenticate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", CacheControlPrivate)
+
 	var req AuthRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("Failed to decode authentication request", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		requestLogger(r, h.logger).Error("Failed to decode authentication request", err)
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "Invalid request body")
 		return
 	}
 
@@ -139,8 +207,8 @@ func (h *AccountHandler) Authenticate(w http.ResponseWriter, r *http.Request) {
 		Password: req.Password,
 	}
 
-	output, err := h.authenticateUC.Execute(input)
-	if h.handleError(w, err) {
+	output, err := h.authenticateUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 
@@ -155,19 +223,19 @@ func (h *AccountHandler) Authenticate(w http.ResponseWriter, r *http.Request) {
 func (h *AccountHandler) SaveToCollection(w http.ResponseWriter, r *http.Request) {
 	var req CollectionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("Failed to decode collection request", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		requestLogger(r, h.logger).Error("Failed to decode collection request", err)
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "Invalid request body")
 		return
 	}
 
 	if req.Collection != "favorite" && req.Collection != "watchlist" {
-		http.Error(w, "Invalid collection", http.StatusBadRequest)
+		h.handleError(w, r, repository.ErrInvalidCollectionType)
 		return
 	}
 
 	email, ok := r.Context().Value(emailContextKey).(string)
 	if !ok {
-		http.Error(w, "Unable to retrieve email", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
 		return
 	}
 
@@ -177,19 +245,30 @@ func (h *AccountHandler) SaveToCollection(w http.ResponseWriter, r *http.Request
 		Collection: req.Collection,
 	}
 
-	output, err := h.saveToCollectionUC.Execute(input)
-	if h.handleError(w, err) {
+	output, err := h.saveToCollectionUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 
+	// Append to the activity stream read by followers' feeds
+	if h.recordActivityUC != nil && !output.AlreadyInCollection {
+		activityInput := socialuc.RecordActivityInput{
+			Email:     email,
+			EventType: entity.ActivityTypeForCollection(req.Collection),
+			MovieID:   req.MovieID,
+		}
+		if err := h.recordActivityUC.Execute(r.Context(), activityInput); err != nil {
+			requestLogger(r, h.logger).Error("Failed to record collection activity", err)
+		}
+	}
+
 	// Fire-and-forget: recompute user embedding and invalidate recommendations
 	if h.updateUserRecommendationsUC != nil && !output.AlreadyInCollection {
-		go func() {
+		h.runInBackground(context.WithoutCancel(r.Context()), "update_recommendations", func(ctx context.Context) error {
 			recInput := movieuc.UpdateUserRecommendationsInput{Email: email}
-			if _, err := h.updateUserRecommendationsUC.Execute(recInput); err != nil {
-				h.logger.Error("Failed to update user recommendations in background", err)
-			}
-		}()
+			_, err := h.updateUserRecommendationsUC.Execute(ctx, recInput)
+			return err
+		})
 	}
 
 	response := AuthResponse{
@@ -199,16 +278,131 @@ func (h *AccountHandler) SaveToCollection(w http.ResponseWriter, r *http.Request
 	h.writeJSONResponse(w, response)
 }
 
+// importLists are the multipart file fields accepted by ImportCollection,
+// one per export list.
+var importLists = []string{csvimport.ListWatched, csvimport.ListRatings, csvimport.ListWatchlist}
+
+// ImportCollection accepts Letterboxd or IMDb CSV exports as multipart files
+// named after the list they contain (watched, ratings, watchlist). Without
+// commit=true it only reports how rows would be matched.
+func (h *AccountHandler) ImportCollection(w http.ResponseWriter, r *http.Request) {
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	if err := r.ParseMultipartForm(importMaxMemory); err != nil {
+		requestLogger(r, h.logger).Error("Failed to parse import upload", err)
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "Invalid multipart upload")
+		return
+	}
+
+	var rows []models.ImportRow
+	for _, list := range importLists {
+		file, _, err := r.FormFile(list)
+		if err == http.ErrMissingFile {
+			continue
+		}
+		if err != nil {
+			writeInvalidField(w, r, list, "Invalid "+list+" file")
+			return
+		}
+
+		parsed, err := csvimport.Parse(file, list)
+		file.Close()
+		if err != nil {
+			requestLogger(r, h.logger).Error("Failed to parse import file", err, "list", list)
+			writeInvalidField(w, r, list, "Invalid "+list+" file: "+err.Error())
+			return
+		}
+		rows = append(rows, parsed...)
+	}
+
+	if len(rows) == 0 {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "No import rows found; upload watched, ratings or watchlist CSV files")
+		return
+	}
+
+	input := accountuc.ImportCollectionInput{
+		Email:  email,
+		Rows:   rows,
+		Commit: r.FormValue("commit") == "true",
+	}
+
+	output, err := h.importCollectionUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	// Refresh recommendations once for the whole import rather than per row
+	if h.updateUserRecommendationsUC != nil && output.Report.Imported > 0 {
+		h.runInBackground(context.WithoutCancel(r.Context()), "import_recommendations", func(ctx context.Context) error {
+			recInput := movieuc.UpdateUserRecommendationsInput{Email: email}
+			_, err := h.updateUserRecommendationsUC.Execute(ctx, recInput)
+			return err
+		})
+	}
+
+	h.writeJSONResponse(w, output.Report)
+}
+
+// ExportCollection streams the user's favorites and watchlist as csv, json
+// or a Letterboxd-compatible CSV, optionally limited by ?collection=.
+func (h *AccountHandler) ExportCollection(w http.ResponseWriter, r *http.Request) {
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	writer, ok := newExportWriter(w, format)
+	if !ok {
+		writeInvalidField(w, r, "format", "Invalid format; use csv, json or letterboxd")
+		return
+	}
+
+	collection := r.URL.Query().Get("collection")
+	if collection != "" && collection != entity.CollectionFavorites && collection != entity.CollectionWatchlist {
+		h.handleError(w, r, repository.ErrInvalidCollectionType)
+		return
+	}
+
+	input := accountuc.ExportCollectionInput{
+		Email:      email,
+		Collection: collection,
+		Write:      writer.Write,
+	}
+
+	if _, err := h.exportCollectionUC.Execute(r.Context(), input); err != nil {
+		// Once streaming has started the status is already sent; leave the
+		// file truncated rather than closing it as if it were complete.
+		if !writer.Started() {
+			h.handleError(w, r, err)
+			return
+		}
+		requestLogger(r, h.logger).Error("Collection export interrupted", err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		requestLogger(r, h.logger).Error("Failed to finish collection export", err)
+	}
+}
+
 func (h *AccountHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
 	email, ok := r.Context().Value(emailContextKey).(string)
 	if !ok {
-		http.Error(w, "Unable to retrieve email", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
 		return
 	}
 
-	input := accountuc.GetFavoritesInput{Email: email}
-	output, err := h.getFavoritesUC.Execute(input)
-	if h.handleError(w, err) {
+	input := accountuc.GetFavoritesInput{Email: email, Include: parseInclude(r)}
+	output, err := h.getFavoritesUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 
@@ -218,13 +412,13 @@ func (h *AccountHandler) GetFavorites(w http.ResponseWriter, r *http.Request) {
 func (h *AccountHandler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
 	email, ok := r.Context().Value(emailContextKey).(string)
 	if !ok {
-		http.Error(w, "Unable to retrieve email", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
 		return
 	}
 
-	input := accountuc.GetWatchlistInput{Email: email}
-	output, err := h.getWatchlistUC.Execute(input)
-	if h.handleError(w, err) {
+	input := accountuc.GetWatchlistInput{Email: email, Include: parseInclude(r)}
+	output, err := h.getWatchlistUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 
@@ -233,9 +427,12 @@ func (h *AccountHandler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
 
 func (h *AccountHandler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Everything behind authentication is specific to the caller
+		w.Header().Set("Cache-Control", CacheControlPrivate)
+
 		tokenStr := r.Header.Get("Authorization")
 		if tokenStr == "" {
-			http.Error(w, "Missing authorization token", http.StatusUnauthorized)
+			writeUnauthorized(w, r, "Missing authorization token")
 			return
 		}
 
@@ -246,23 +443,23 @@ func (h *AccountHandler) AuthMiddleware(next http.Handler) http.Handler {
 				if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 					return nil, jwt.ErrSignatureInvalid
 				}
-				return []byte(token.GetJWTSecret(*h.logger)), nil
+				return h.jwtSecret, nil
 			},
 		)
 		if err != nil || !parsedToken.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			writeUnauthorized(w, r, "Invalid token")
 			return
 		}
 
 		claims, ok := parsedToken.Claims.(jwt.MapClaims)
 		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			writeUnauthorized(w, r, "Invalid token claims")
 			return
 		}
 
 		email, ok := claims["email"].(string)
 		if !ok {
-			http.Error(w, "Email not found in token", http.StatusUnauthorized)
+			writeUnauthorized(w, r, "Email not found in token")
 			return
 		}
 
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+	// brotliLevel trades some ratio for speed; responses are compressed on
+	// every request rather than ahead of time.
+	brotliLevel = 5
+)
+
+// compressibleTypes are the media types worth compressing. Images, fonts
+// and archives are already compressed and pass through untouched.
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/manifest+json",
+	"application/xml",
+	"application/rss+xml",
+	"image/svg+xml",
+}
+
+var (
+	gzipWriters   = sync.Pool{New: func() any { w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression); return w }}
+	brotliWriters = sync.Pool{New: func() any { return brotli.NewWriterLevel(nil, brotliLevel) }}
+)
+
+// Compress encodes responses with brotli or gzip, whichever the client
+// prefers in Accept-Encoding (brotli on a tie). Only 200 responses of a
+// compressible type and at least cfg.MinSize bytes are compressed; bodies
+// of unknown length are held back until that much has been written.
+// Compressed responses get a weak ETag, since their bytes differ from the
+// identity encoding the handler tagged.
+func Compress(cfg config.CompressionConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := ""
+			if r.Method != http.MethodHead {
+				encoding = negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			}
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: cfg.MinSize}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks the supported coding with the highest q-value,
+// or "" when the client accepts neither.
+func negotiateEncoding(acceptEncoding string) string {
+	quality := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding != "" {
+			quality[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingBrotli, encodingGzip} {
+		q, ok := quality[coding]
+		if !ok {
+			q, ok = quality["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter decides whether to compress once the status, headers and
+// enough of the body are known. It forwards Flush so streamed exports keep
+// working, compressed or not.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	decided bool
+	pending []byte
+	encoder io.WriteCloser
+	// err is the first error writing to the client; every later Write
+	// returns it.
+	err error
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.decided || cw.status != 0 {
+		return
+	}
+	cw.status = status
+	if status != http.StatusOK {
+		if status == http.StatusNotModified && cw.encoding != "" {
+			// Match the ETag a compressed 200 would have carried
+			weakenETag(cw.Header())
+		}
+		cw.decide(false)
+		return
+	}
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		cw.decide(false)
+		return
+	}
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		if !compressible(contentType) {
+			cw.decide(false)
+			return
+		}
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil {
+		cw.decide(length >= cw.minSize)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	if cw.decided {
+		var n int
+		if cw.encoder != nil {
+			n, cw.err = cw.encoder.Write(p)
+		} else {
+			n, cw.err = cw.ResponseWriter.Write(p)
+		}
+		return n, cw.err
+	}
+
+	cw.pending = append(cw.pending, p...)
+	if len(cw.pending) >= cw.minSize {
+		if err := cw.decide(cw.sniffCompressible()); err != nil {
+			// Some of p may have gone out, but not all of it
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		// A streamed body: compress it if the type allows, whatever its size
+		cw.decide(cw.sniffCompressible())
+	}
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// sniffCompressible fills in Content-Type from the body held so far, as
+// net/http would, and reports whether it is worth compressing.
+func (cw *compressWriter) sniffCompressible() bool {
+	header := cw.Header()
+	if header.Get("Content-Type") == "" {
+		if len(cw.pending) == 0 {
+			return false
+		}
+		header.Set("Content-Type", http.DetectContentType(cw.pending))
+		if !compressible(header.Get("Content-Type")) {
+			return false
+		}
+		header.Add("Vary", "Accept-Encoding")
+	}
+	return true
+}
+
+// decide sends the headers, compressed or not, followed by anything held
+// back so far. A failure to send what was held back is kept in cw.err, so
+// callers without an error to return still fail the next Write.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	header := cw.Header()
+	if compress && cw.encoding != "" {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		weakenETag(header)
+		cw.encoder = newEncoder(cw.encoding, cw.ResponseWriter)
+	}
+	if cw.status != 0 {
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+	if len(cw.pending) > 0 {
+		pending := cw.pending
+		cw.pending = nil
+		if cw.encoder != nil {
+			_, cw.err = cw.encoder.Write(pending)
+		} else {
+			_, cw.err = cw.ResponseWriter.Write(pending)
+		}
+	}
+	return cw.err
+}
+
+// close sends a body too small to compress and finishes a compressed one.
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if cw.status == 0 && len(cw.pending) == 0 {
+			return
+		}
+		cw.sniffCompressible()
+		cw.decide(false)
+	}
+	if cw.encoder != nil {
+		cw.encoder.Close()
+		releaseEncoder(cw.encoder)
+		cw.encoder = nil
+	}
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == encodingBrotli {
+		bw := brotliWriters.Get().(*brotli.Writer)
+		bw.Reset(w)
+		return bw
+	}
+	gw := gzipWriters.Get().(*gzip.Writer)
+	gw.Reset(w)
+	return gw
+}
+
+func releaseEncoder(encoder io.WriteCloser) {
+	switch e := encoder.(type) {
+	case *brotli.Writer:
+		brotliWriters.Put(e)
+	case *gzip.Writer:
+		gzipWriters.Put(e)
+	}
+}
+
+func weakenETag(header http.Header) {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+}
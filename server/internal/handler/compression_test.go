@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+var testCompression = config.CompressionConfig{Enabled: true, MinSize: 1024}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", encodingGzip},
+		{"br", encodingBrotli},
+		{"GZIP", encodingGzip},
+		// Equal quality goes to brotli
+		{"gzip, br", encodingBrotli},
+		{"gzip;q=0.5, br;q=0.5", encodingBrotli},
+		{"gzip;q=1.0, br;q=0.8", encodingGzip},
+		{"gzip ; q=0.9 , br ; q=0.1", encodingGzip},
+		// q=0 means not acceptable
+		{"br;q=0", ""},
+		{"br;q=0, gzip", encodingGzip},
+		{"gzip;q=0, br;q=0", ""},
+		// * stands for every coding not listed
+		{"*", encodingBrotli},
+		{"*;q=0", ""},
+		{"br;q=0, *", encodingGzip},
+		{"*;q=0.5, gzip", encodingGzip},
+		{"deflate, *;q=0", ""},
+		// An unparsable q is ignored
+		{"gzip;q=abc", encodingGzip},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+// serveCompressed runs h behind Compress and returns the response.
+func serveCompressed(h http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	rec := httptest.NewRecorder()
+	Compress(testCompression)(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch rec.Header().Get("Content-Encoding") {
+	case encodingGzip:
+		gr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gr
+	case encodingBrotli:
+		r = brotli.NewReader(rec.Body)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+// writeInChunks writes body in 100-byte writes, as an encoder would, so
+// Compress never sees its length up front.
+func writeInChunks(contentType, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		for rest := body; len(rest) > 0; {
+			n := min(100, len(rest))
+			io.WriteString(w, rest[:n])
+			rest = rest[n:]
+		}
+	}
+}
+
+func TestCompressHoldsBackUntilMinSize(t *testing.T) {
+	small := strings.Repeat("a", testCompression.MinSize-1)
+	large := strings.Repeat("a", testCompression.MinSize)
+
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		body         string
+		wantEncoding string
+	}{
+		{"below min size", writeInChunks("application/json", small), small, ""},
+		{"at min size", writeInChunks("application/json", large), large, encodingGzip},
+		{"sniffed type", writeInChunks("", large), large, encodingGzip},
+		{"incompressible type", writeInChunks("image/png", large), large, ""},
+		{"small body with length", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", strconv.Itoa(len(small)))
+			io.WriteString(w, small)
+		}, small, ""},
+		{"already encoded", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "identity")
+			io.WriteString(w, large)
+		}, large, "identity"},
+		{"error status", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, large)
+		}, large, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveCompressed(tt.handler, "gzip")
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := decodeBody(t, rec); got != tt.body {
+				t.Errorf("body is %d bytes, want %d", len(got), len(tt.body))
+			}
+			if tt.wantEncoding == encodingGzip && rec.Header().Get("Content-Length") != "" {
+				t.Errorf("compressed response kept Content-Length %s", rec.Header().Get("Content-Length"))
+			}
+		})
+	}
+}
+
+func TestCompressWeakensETagOnNotModified(t *testing.T) {
+	body := strings.Repeat(`{"title":"Movie"}`, 100)
+	h := Cached(config.CachePolicy{MaxAge: time.Minute})(writeInChunks("application/json", body))
+	serve := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		rec := httptest.NewRecorder()
+		Compress(testCompression)(h).ServeHTTP(rec, req)
+		return rec
+	}
+
+	identity := serve("", "")
+	strong := identity.Header().Get("ETag")
+	if strong == "" || strings.HasPrefix(strong, "W/") {
+		t.Fatalf("identity ETag = %q, want a strong tag", strong)
+	}
+
+	compressed := serve("br", "")
+	if got, want := compressed.Header().Get("ETag"), "W/"+strong; got != want {
+		t.Fatalf("compressed ETag = %q, want %q", got, want)
+	}
+	if got := decodeBody(t, compressed); got != body {
+		t.Errorf("compressed body does not decode to the original")
+	}
+
+	// Revalidating the compressed copy matches and keeps the weak tag
+	notModified := serve("br", "W/"+strong)
+	if notModified.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", notModified.Code)
+	}
+	if got := notModified.Header().Get("ETag"); got != "W/"+strong {
+		t.Errorf("304 ETag = %q, want %q", got, "W/"+strong)
+	}
+	if notModified.Header().Get("Content-Encoding") != "" || notModified.Body.Len() != 0 {
+		t.Errorf("304 carries Content-Encoding %q and %d body bytes", notModified.Header().Get("Content-Encoding"), notModified.Body.Len())
+	}
+
+	// Without compression the 304 keeps the strong tag
+	if got := serve("", strong).Header().Get("ETag"); got != strong {
+		t.Errorf("identity 304 ETag = %q, want %q", got, strong)
+	}
+}
+
+func TestCompressFlushesStreamedBody(t *testing.T) {
+	for _, encoding := range []string{encodingGzip, encodingBrotli} {
+		t.Run(encoding, func(t *testing.T) {
+			rows := []string{"title,year\n", "Movie 1,2001\n", "Movie 2,2002\n"}
+			var rec *httptest.ResponseRecorder
+			var flushed []int
+			h := func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/csv")
+				for _, row := range rows {
+					io.WriteString(w, row)
+					if err := http.NewResponseController(w).Flush(); err != nil {
+						t.Fatal(err)
+					}
+					flushed = append(flushed, rec.Body.Len())
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			rec = httptest.NewRecorder()
+			Compress(testCompression)(http.HandlerFunc(h)).ServeHTTP(rec, req)
+
+			if !rec.Flushed {
+				t.Error("the client never saw a flush")
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != encoding {
+				t.Errorf("Content-Encoding = %q, want %s although the body is below min size", got, encoding)
+			}
+			// Every flush sent the row written before it
+			for i := 1; i < len(flushed); i++ {
+				if flushed[i] <= flushed[i-1] {
+					t.Errorf("flush %d sent nothing new (%d bytes so far)", i, flushed[i])
+				}
+			}
+			if got := decodeBody(t, rec); got != strings.Join(rows, "") {
+				t.Errorf("body = %q", got)
+			}
+		})
+	}
+}
+
+// failingWriter accepts the headers but fails every body write.
+type failingWriter struct {
+	http.ResponseWriter
+}
+
+var errClientGone = errors.New("client went away")
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errClientGone }
+
+func TestCompressReportsWriteErrors(t *testing.T) {
+	for _, encoding := range []string{"", encodingGzip} {
+		t.Run("encoding="+encoding, func(t *testing.T) {
+			var errs []error
+			h := func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				// The first write is held back; the second sends it
+				for range 2 {
+					_, err := w.Write(bytes.Repeat([]byte("a"), testCompression.MinSize/2+1))
+					errs = append(errs, err)
+				}
+				_, err := w.Write([]byte("more"))
+				errs = append(errs, err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			w := failingWriter{httptest.NewRecorder()}
+			Compress(testCompression)(http.HandlerFunc(h)).ServeHTTP(w, req)
+
+			if errs[0] != nil {
+				t.Errorf("held-back write failed: %v", errs[0])
+			}
+			for i, err := range errs[1:] {
+				if !errors.Is(err, errClientGone) {
+					t.Errorf("write %d returned %v, want the client's error", i+2, err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompressHomePage renders the home page behind Compress and
+// reports the bytes sent per response next to the identity size.
+func BenchmarkCompressHomePage(b *testing.B) {
+	ssr := newTestSSRHandler(b, testCatalogue(20), nil)
+	h := Compress(testCompression)(http.HandlerFunc(ssr.HomePage))
+
+	identity := httptest.NewRecorder()
+	h.ServeHTTP(identity, httptest.NewRequest(http.MethodGet, "/", nil))
+	if identity.Code != http.StatusOK {
+		b.Fatalf("status = %d", identity.Code)
+	}
+
+	for _, encoding := range []string{"identity", encodingGzip, encodingBrotli} {
+		b.Run(encoding, func(b *testing.B) {
+			var sent int
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Accept-Encoding", encoding)
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				sent = rec.Body.Len()
+			}
+			b.ReportMetric(float64(sent), "sent-bytes/op")
+			b.ReportMetric(float64(identity.Body.Len()), "identity-bytes/op")
+			b.ReportMetric(float64(sent)/float64(identity.Body.Len()), "ratio")
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+var (
+	corsAllowedMethods = strings.Join(probeMethods, ", ")
+	corsAllowedHeaders = strings.Join([]string{"Authorization", "Content-Type", "If-None-Match", RequestIDHeader}, ", ")
+	// corsExposedHeaders are the response headers, beyond the CORS-safelisted
+	// ones, that cross-origin callers may read.
+	corsExposedHeaders = strings.Join([]string{"ETag", "Link", "Deprecation", "Content-Disposition", RequestIDHeader}, ", ")
+)
+
+// CORS lets the configured origins call the routes under prefix from the
+// browser. Preflight requests from an allowed origin are answered here with
+// 204; everything else goes on to next, with the CORS headers added when the
+// Origin is allowed. With no allowed origins it returns next unchanged.
+func CORS(cfg config.CORSConfig, prefix string) func(http.Handler) http.Handler {
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		if len(cfg.AllowedOrigins) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !anyOrigin {
+				header.Add("Vary", "Origin")
+			}
+			if origin == "" || !(anyOrigin || slices.Contains(cfg.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if preflight {
+				header.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+				header.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+				if cfg.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			header.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/entity"
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+const (
+	ExportFormatCSV        = "csv"
+	ExportFormatJSON       = "json"
+	ExportFormatLetterboxd = "letterboxd"
+)
+
+// exportFlushEvery is how many entries are written between flushes so large
+// exports reach the client progressively.
+const exportFlushEvery = 100
+
+// exportWriter encodes collection entries straight to the response. Headers
+// are only sent with the first entry (or on Close for empty exports), so an
+// error before any entry can still be reported with a proper status code.
+type exportWriter interface {
+	Write(entry models.CollectionEntry) error
+	Close() error
+	Started() bool
+}
+
+type exportEntry struct {
+	Collection string `json:"collection"`
+	TMDBID     int    `json:"tmdb_id"`
+	Title      string `json:"title"`
+	Year       int    `json:"year"`
+	TimeAdded  string `json:"time_added,omitempty"`
+}
+
+func newExportWriter(w http.ResponseWriter, format string) (exportWriter, bool) {
+	switch format {
+	case ExportFormatCSV:
+		return &csvExportWriter{w: w, filename: "moovies-export.csv", header: []string{"collection", "tmdb_id", "title", "year", "time_added"}, row: csvExportRow}, true
+	case ExportFormatLetterboxd:
+		return &csvExportWriter{w: w, filename: "moovies-letterboxd.csv", header: []string{"tmdbID", "Title", "Year", "WatchedDate", "Tags"}, row: letterboxdExportRow}, true
+	case ExportFormatJSON:
+		return &jsonExportWriter{w: w}, true
+	default:
+		return nil, false
+	}
+}
+
+func startExport(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", CacheControlPrivate)
+	w.WriteHeader(http.StatusOK)
+}
+
+func flushExport(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func formatTimeAdded(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvExportRow(entry models.CollectionEntry) []string {
+	return []string{
+		entry.Collection,
+		strconv.Itoa(entry.Movie.TMDB_ID),
+		entry.Movie.Title,
+		strconv.Itoa(entry.Movie.ReleaseYear),
+		formatTimeAdded(entry.TimeAdded),
+	}
+}
+
+// letterboxdExportRow follows Letterboxd's import columns. Favorites carry
+// the date they were added as the watched date; the collection goes in Tags.
+func letterboxdExportRow(entry models.CollectionEntry) []string {
+	watchedDate := ""
+	if entry.Collection == entity.CollectionFavorites && !entry.TimeAdded.IsZero() {
+		watchedDate = entry.TimeAdded.UTC().Format("2006-01-02")
+	}
+	return []string{
+		strconv.Itoa(entry.Movie.TMDB_ID),
+		entry.Movie.Title,
+		strconv.Itoa(entry.Movie.ReleaseYear),
+		watchedDate,
+		entry.Collection,
+	}
+}
+
+type csvExportWriter struct {
+	w        http.ResponseWriter
+	csv      *csv.Writer
+	filename string
+	header   []string
+	row      func(models.CollectionEntry) []string
+	count    int
+}
+
+func (e *csvExportWriter) start() error {
+	if e.csv != nil {
+		return nil
+	}
+	startExport(e.w, "text/csv; charset=utf-8", e.filename)
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(e.header)
+}
+
+func (e *csvExportWriter) Write(entry models.CollectionEntry) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if err := e.csv.Write(e.row(entry)); err != nil {
+		return err
+	}
+	e.count++
+	if e.count%exportFlushEvery == 0 {
+		e.csv.Flush()
+		flushExport(e.w)
+	}
+	return e.csv.Error()
+}
+
+func (e *csvExportWriter) Started() bool { return e.csv != nil }
+
+func (e *csvExportWriter) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+type jsonExportWriter struct {
+	w       http.ResponseWriter
+	started bool
+	count   int
+}
+
+func (e *jsonExportWriter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	startExport(e.w, "application/json", "moovies-export.json")
+	_, err := io.WriteString(e.w, `{"entries":[`)
+	return err
+}
+
+func (e *jsonExportWriter) Write(entry models.CollectionEntry) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(exportEntry{
+		Collection: entry.Collection,
+		TMDBID:     entry.Movie.TMDB_ID,
+		Title:      entry.Movie.Title,
+		Year:       entry.Movie.ReleaseYear,
+		TimeAdded:  formatTimeAdded(entry.TimeAdded),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	e.count++
+	if e.count%exportFlushEvery == 0 {
+		flushExport(e.w)
+	}
+	return nil
+}
+
+func (e *jsonExportWriter) Started() bool { return e.started }
+
+func (e *jsonExportWriter) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
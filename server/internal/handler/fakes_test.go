@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// testIndexHTML has the markers the SSR layout cuts index.html at.
+const testIndexHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Movies</title>
+  <script type="module" src="/assets/app.js"></script>
+</head>
+<body>
+  <header><h1>Movies</h1></header>
+  <main></main>
+  <footer>Movies</footer>
+</body>
+</html>
+`
+
+// fakeMovieRepository serves a fixed catalogue from memory. Methods it
+// does not implement panic through the nil embedded interface.
+type fakeMovieRepository struct {
+	repository.MovieRepository
+	movies []models.Movie
+	genres []models.Genre
+}
+
+func (f *fakeMovieRepository) GetTopMovies(ctx context.Context) ([]models.Movie, error) {
+	return f.movies, nil
+}
+
+func (f *fakeMovieRepository) GetRandomMovies(ctx context.Context) ([]models.Movie, error) {
+	return f.movies, nil
+}
+
+func (f *fakeMovieRepository) GetMovieByID(ctx context.Context, id int) (models.Movie, error) {
+	for _, m := range f.movies {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return models.Movie{}, repository.ErrMovieNotFound
+}
+
+func (f *fakeMovieRepository) SearchMoviesByName(ctx context.Context, query string, order string, genre *int) ([]models.Movie, error) {
+	return f.movies, nil
+}
+
+func (f *fakeMovieRepository) GetAllGenres(ctx context.Context) ([]models.Genre, error) {
+	return f.genres, nil
+}
+
+// fakeSitemapRepository lists movies from memory and counts the pages
+// streamed. When release is set, StreamMoviePage blocks until it is closed.
+type fakeSitemapRepository struct {
+	movies   []models.SitemapEntry
+	genres   []models.SitemapEntry
+	streamed atomic.Int32
+	release  chan struct{}
+}
+
+func (f *fakeSitemapRepository) GetMoviePages(ctx context.Context, size int) ([]time.Time, error) {
+	var pages []time.Time
+	for i := 0; i < len(f.movies); i += size {
+		pages = append(pages, f.movies[min(i+size, len(f.movies))-1].LastModified)
+	}
+	return pages, nil
+}
+
+func (f *fakeSitemapRepository) StreamMoviePage(ctx context.Context, page int, size int, fn func(models.SitemapEntry) error) error {
+	f.streamed.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	for i := page * size; i < len(f.movies) && i < (page+1)*size; i++ {
+		if err := fn(f.movies[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeSitemapRepository) GetGenres(ctx context.Context) ([]models.SitemapEntry, error) {
+	return f.genres, nil
+}
+
+// fakeFeedRepository is one user whose feed token is always valid, with
+// the same entries in every collection.
+type fakeFeedRepository struct {
+	entries []models.CollectionEntry
+}
+
+func (f *fakeFeedRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	return 1, nil
+}
+
+func (f *fakeFeedRepository) SaveFeedToken(ctx context.Context, userID int, tokenHash string) error {
+	return nil
+}
+
+func (f *fakeFeedRepository) RevokeFeedToken(ctx context.Context, userID int) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeFeedRepository) GetUserIDByFeedToken(ctx context.Context, tokenHash string) (int, error) {
+	return 1, nil
+}
+
+func (f *fakeFeedRepository) GetCollectionVersion(ctx context.Context, userID int, collectionType string) (time.Time, int, error) {
+	return f.entries[0].TimeAdded, len(f.entries), nil
+}
+
+func (f *fakeFeedRepository) GetCollectionEntries(ctx context.Context, userID int, collectionType string, limit int) ([]models.CollectionEntry, error) {
+	return f.entries, nil
+}
+
+func (f *fakeFeedRepository) GetRecommendationsVersion(ctx context.Context, userID int) (time.Time, int, error) {
+	return f.entries[0].TimeAdded, len(f.entries), nil
+}
+
+func (f *fakeFeedRepository) GetRecommendationEntries(ctx context.Context, userID int, limit int) ([]models.CollectionEntry, error) {
+	return f.entries, nil
+}
+
+// testCatalogue returns n movies with every field the pages show filled in.
+func testCatalogue(n int) *fakeMovieRepository {
+	genres := []models.Genre{{ID: 1, Name: "Drama"}, {ID: 2, Name: "Science Fiction"}}
+	repo := &fakeMovieRepository{genres: genres}
+	for i := 1; i <= n; i++ {
+		tagline := fmt.Sprintf("Tagline of movie %d", i)
+		overview := fmt.Sprintf("Overview of movie %d, long enough to read like the synopsis of a real film in the catalogue.", i)
+		poster := fmt.Sprintf("https://image.tmdb.org/t/p/w500/poster-%d.jpg", i)
+		score := float32(7.5)
+		repo.movies = append(repo.movies, models.Movie{
+			ID:          i,
+			TMDB_ID:     1000 + i,
+			Title:       fmt.Sprintf("Movie %d", i),
+			Tagline:     &tagline,
+			ReleaseYear: 2000 + i%20,
+			Overview:    &overview,
+			Score:       &score,
+			PosterURL:   &poster,
+			Genres:      genres,
+		})
+	}
+	return repo
+}
+
+func testLogger(tb testing.TB) *logger.Logger {
+	tb.Helper()
+	log, err := logger.New(logger.Options{Level: "error"})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return log
+}
+
+// testPublicURL is the configured public URL of the test handlers.
+const testPublicURL = "https://movies.example.com"
+
+// newTestSSRHandler renders pages from the embedded templates around
+// testIndexHTML, with data from repo.
+func newTestSSRHandler(tb testing.TB, repo repository.MovieRepository, m *metrics.Metrics) *SSRHandler {
+	tb.Helper()
+	publicDir := tb.TempDir()
+	if err := os.WriteFile(filepath.Join(publicDir, "index.html"), []byte(testIndexHTML), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	log := testLogger(tb)
+	h, err := NewSSRHandler(NewMovieHandler(repo, nil, log), publicDir, testPublicURL, config.SSRConfig{}, m, log)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return h
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/usecase/feeds"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type FeedTokenResponse struct {
+	Success            bool   `json:"success"`
+	Message            string `json:"message"`
+	Token              string `json:"token,omitempty"`
+	WatchlistURL       string `json:"watchlist_url,omitempty"`
+	RecommendationsURL string `json:"recommendations_url,omitempty"`
+	DiaryURL           string `json:"diary_url,omitempty"`
+}
+
+type FeedHandler struct {
+	createFeedTokenUC *feeds.CreateFeedTokenUseCase
+	revokeFeedTokenUC *feeds.RevokeFeedTokenUseCase
+	getPersonalFeedUC *feeds.GetPersonalFeedUseCase
+	publicURL         string
+	logger            *logger.Logger
+}
+
+// NewFeedHandler builds feed URLs, and the links inside feeds, from
+// publicURL, never from the Host a request was sent to.
+func NewFeedHandler(repo repository.FeedRepository, publicURL string, log *logger.Logger) *FeedHandler {
+	return &FeedHandler{
+		createFeedTokenUC: feeds.NewCreateFeedTokenUseCase(repo, log),
+		revokeFeedTokenUC: feeds.NewRevokeFeedTokenUseCase(repo, log),
+		getPersonalFeedUC: feeds.NewGetPersonalFeedUseCase(repo, log),
+		publicURL:         publicURL,
+		logger:            log,
+	}
+}
+
+func (h *FeedHandler) writeJSONResponse(w http.ResponseWriter, data interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode response", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return err
+	}
+	return nil
+}
+
+// handleError reports every missing feed the same way so that a wrong
+// token reveals nothing about which part of the URL was wrong.
+func (h *FeedHandler) handleError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch err {
+	case nil:
+		return false
+	case repository.ErrUserNotFound, repository.ErrFeedTokenNotFound, repository.ErrInvalidFeedKind:
+		writeProblem(w, r, http.StatusNotFound, "feed_not_found", "Feed not found")
+	default:
+		writeError(w, r, h.logger, err)
+	}
+	return true
+}
+
+// CreateFeedToken issues the user's feed token. Issuing a new token
+// invalidates the previous feed URLs.
+func (h *FeedHandler) CreateFeedToken(w http.ResponseWriter, r *http.Request) {
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	output, err := h.createFeedTokenUC.Execute(r.Context(), feeds.CreateFeedTokenInput{Email: email})
+	if h.handleError(w, r, err) {
+		return
+	}
+	base := h.publicURL + "/feeds/" + output.Token
+	w.Header().Set("Cache-Control", CacheControlPrivate)
+	h.writeJSONResponse(w, FeedTokenResponse{
+		Success:            true,
+		Message:            "Feed token created",
+		Token:              output.Token,
+		WatchlistURL:       base + "/watchlist.rss",
+		RecommendationsURL: base + "/recommendations.rss",
+		DiaryURL:           base + "/diary.ics",
+	})
+}
+
+// RevokeFeedToken deletes the user's feed token, disabling their feeds.
+func (h *FeedHandler) RevokeFeedToken(w http.ResponseWriter, r *http.Request) {
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	output, err := h.revokeFeedTokenUC.Execute(r.Context(), feeds.RevokeFeedTokenInput{Email: email})
+	if h.handleError(w, r, err) {
+		return
+	}
+	h.writeJSONResponse(w, FeedTokenResponse{Success: output.Success, Message: output.Message})
+}
+
+// feedFiles maps the file part of /feeds/{token}/{file} to a feed kind.
+var feedFiles = map[string]string{
+	"watchlist.rss":       feeds.FeedWatchlist,
+	"recommendations.rss": feeds.FeedRecommendations,
+	"diary.ics":           feeds.FeedDiary,
+}
+
+// ServeFeed serves /feeds/{token}/watchlist.rss, recommendations.rss and
+// diary.ics. The secret token in the path replaces the Authorization header,
+// which feed readers and calendar apps cannot send.
+func (h *FeedHandler) ServeFeed(w http.ResponseWriter, r *http.Request) {
+	kind, ok := feedFiles[r.PathValue("file")]
+	if !ok {
+		h.handleError(w, r, repository.ErrInvalidFeedKind)
+		return
+	}
+
+	input := feeds.GetPersonalFeedInput{
+		Token:       r.PathValue("token"),
+		Kind:        kind,
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+	}
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		input.IfModifiedSince = since
+	}
+
+	output, err := h.getPersonalFeedUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	w.Header().Set("ETag", output.ETag)
+	if !output.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", output.LastModified.Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
+	// Keep the secret URL out of Referer headers sent from feed readers
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	if output.NotModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	base := h.publicURL
+	var body []byte
+	if kind == feeds.FeedDiary {
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		body = renderDiaryICal(base, output.Entries)
+	} else {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		body, err = renderRSS(base, kind, output)
+		if err != nil {
+			requestLogger(r, h.logger).Error("Failed to render feed", err, "kind", kind)
+			writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "An unexpected error occurred")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate,omitempty"`
+	Description string       `xml:"description,omitempty"`
+	Enclosure   *rssEnclosed `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosed struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+func renderRSS(base string, kind string, output *feeds.GetPersonalFeedOutput) ([]byte, error) {
+	channel := rssChannel{
+		Title:       "Moovies - My Watchlist",
+		Link:        base + "/account/watchlist",
+		Description: "Movies on your Moovies watchlist",
+	}
+	if kind == feeds.FeedRecommendations {
+		channel = rssChannel{
+			Title:       "Moovies - Recommended for You",
+			Link:        base + "/",
+			Description: "Your latest personalised movie recommendations",
+		}
+	}
+	if !output.LastModified.IsZero() {
+		channel.LastBuildDate = output.LastModified.Format(time.RFC1123Z)
+	}
+
+	for _, entry := range output.Entries {
+		link := base + "/movies/" + strconv.Itoa(entry.Movie.ID)
+		item := rssItem{
+			Title: entryTitle(entry.Movie),
+			Link:  link,
+			GUID:  rssGUID{Value: kind + ":" + strconv.Itoa(entry.Movie.ID) + ":" + strconv.FormatInt(entry.TimeAdded.Unix(), 10)},
+		}
+		if !entry.TimeAdded.IsZero() {
+			item.PubDate = entry.TimeAdded.UTC().Format(time.RFC1123Z)
+		}
+		if entry.Movie.Overview != nil {
+			item.Description = *entry.Movie.Overview
+		}
+		if entry.Movie.PosterURL != nil && *entry.Movie.PosterURL != "" {
+			item.Enclosure = &rssEnclosed{URL: *entry.Movie.PosterURL, Type: "image/jpeg"}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	data, err := xml.MarshalIndent(rssDocument{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func entryTitle(m models.Movie) string {
+	return fmt.Sprintf("%s (%d)", m.Title, m.ReleaseYear)
+}
+
+// renderDiaryICal renders one all-day VEVENT per watched movie (RFC 5545).
+func renderDiaryICal(base string, entries []models.CollectionEntry) []byte {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//Moovies//Watch Diary//EN\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString("X-WR-CALNAME:Moovies Watch Diary\r\n")
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	host := strings.TrimPrefix(strings.TrimPrefix(base, "https://"), "http://")
+	for _, entry := range entries {
+		if entry.TimeAdded.IsZero() {
+			continue
+		}
+		day := entry.TimeAdded.UTC()
+		b.WriteString("BEGIN:VEVENT\r\n")
+		writeICalLine(&b, "UID:diary-"+strconv.Itoa(entry.Movie.ID)+"-"+day.Format("20060102")+"@"+host)
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+day.Format("20060102"))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"))
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(entryTitle(entry.Movie)))
+		if entry.Movie.Overview != nil {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(*entry.Movie.Overview))
+		}
+		writeICalLine(&b, "URL:"+base+"/movies/"+strconv.Itoa(entry.Movie.ID))
+		b.WriteString("END:VEVENT\r\n")
+	}
+
+	b.WriteString("END:VCALENDAR\r\n")
+	return []byte(b.String())
+}
+
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\r\n", `\n`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
+
+// writeICalLine folds content lines longer than 75 octets as RFC 5545
+// requires, without splitting UTF-8 sequences.
+func writeICalLine(b *strings.Builder, line string) {
+	maxOctets := 75
+	for len(line) > maxOctets {
+		cut := maxOctets
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		maxOctets = 74 // the leading space counts towards the limit
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+func TestFeedsIgnoreRequestHost(t *testing.T) {
+	added := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeFeedRepository{entries: []models.CollectionEntry{
+		{Collection: "watchlist", TimeAdded: added, Movie: models.Movie{ID: 603, Title: "The Matrix", ReleaseYear: 1999}},
+	}}
+	h := NewFeedHandler(repo, testPublicURL, testLogger(t))
+
+	forged := func(r *http.Request) *http.Request {
+		r.Host = "attacker.example"
+		r.Header.Set("X-Forwarded-Proto", "http")
+		return r
+	}
+
+	t.Run("token", func(t *testing.T) {
+		req := forged(httptest.NewRequest(http.MethodPost, "/api/v1/account/feed-token", nil))
+		req = req.WithContext(context.WithValue(req.Context(), emailContextKey, "user@example.com"))
+		rec := httptest.NewRecorder()
+		h.CreateFeedToken(rec, req)
+
+		var resp FeedTokenResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range []string{resp.WatchlistURL, resp.RecommendationsURL, resp.DiaryURL} {
+			if !strings.HasPrefix(u, testPublicURL+"/feeds/"+resp.Token+"/") {
+				t.Errorf("feed URL %q does not start with %s", u, testPublicURL)
+			}
+		}
+	})
+
+	for _, file := range []string{"watchlist.rss", "recommendations.rss", "diary.ics"} {
+		t.Run(file, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /feeds/{token}/{file}", h.ServeFeed)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, forged(httptest.NewRequest(http.MethodGet, "/feeds/"+testFeedToken+"/"+file, nil)))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			body := rec.Body.String()
+			if strings.Contains(body, "attacker.example") {
+				t.Error("feed links to the request's Host")
+			}
+			if !strings.Contains(body, testPublicURL+"/movies/603") {
+				t.Errorf("feed does not link the movie under %s:\n%s", testPublicURL, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+// CacheControlPrivate marks responses that depend on the caller and must
+// never be stored by a browser or shared cache.
+const CacheControlPrivate = "private, no-store"
+
+// Cached makes successful GET and HEAD responses of next cacheable: it
+// buffers the body, tags it with an ETag computed from its content,
+// answers a matching If-None-Match with 304 and sets Cache-Control from
+// policy unless next already chose one. Other responses pass through.
+func Cached(policy config.CachePolicy) func(http.Handler) http.Handler {
+	cacheControl := cacheControlFor(policy)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferedResponse{ResponseWriter: w}
+			next.ServeHTTP(buf, r)
+			if buf.status == 0 {
+				buf.status = http.StatusOK
+			}
+			if buf.status != http.StatusOK {
+				w.WriteHeader(buf.status)
+				w.Write(buf.body.Bytes())
+				return
+			}
+
+			header := w.Header()
+			etag := header.Get("ETag")
+			if etag == "" {
+				etag = contentETag(buf.body.Bytes(), CSPNonce(r.Context()))
+				header.Set("ETag", etag)
+			}
+			if header.Get("Cache-Control") == "" {
+				header.Set("Cache-Control", cacheControl)
+			}
+
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				header.Del("Content-Type")
+				header.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			header.Set("Content-Length", strconv.Itoa(buf.body.Len()))
+			w.WriteHeader(http.StatusOK)
+			w.Write(buf.body.Bytes())
+		})
+	}
+}
+
+// cacheControlFor renders policy as a public Cache-Control value.
+func cacheControlFor(policy config.CachePolicy) string {
+	if policy.MaxAge <= 0 && policy.StaleWhileRevalidate <= 0 {
+		return "public, no-cache"
+	}
+	value := "public, max-age=" + strconv.Itoa(int(policy.MaxAge.Seconds()))
+	if policy.StaleWhileRevalidate > 0 {
+		value += ", stale-while-revalidate=" + strconv.Itoa(int(policy.StaleWhileRevalidate.Seconds()))
+	}
+	return value
+}
+
+// contentETag tags body by its content: identical bodies always get the
+// same strong tag, so every replica agrees without sharing state. A page
+// embedding the request's CSP nonce differs on every request, so the nonce
+// is left out of the hash and the tag is weak: such pages are equivalent,
+// not identical.
+func contentETag(body []byte, nonce string) string {
+	weak := ""
+	if nonce != "" && bytes.Contains(body, []byte(nonce)) {
+		body = bytes.ReplaceAll(body, []byte(nonce), nil)
+		weak = "W/"
+	}
+	sum := sha256.Sum256(body)
+	return weak + `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches applies the weak comparison RFC 9110 prescribes for
+// If-None-Match to a comma-separated list of entity tags or "*".
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse holds the status and body back until the ETag is known.
+// Headers go straight to the underlying writer.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
@@ -3,7 +3,9 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/usecase/movie"
@@ -45,40 +47,53 @@ func (h *MovieHandler) writeJSONResponse(w http.ResponseWriter, data interface{}
 	return nil
 }
 
-func (h *MovieHandler) handleError(w http.ResponseWriter, err error, context string) bool {
-	if err != nil {
-		if err == repository.ErrMovieNotFound {
-			http.Error(w, context, http.StatusNotFound)
-			return true
-		}
-		h.logger.Error(context, err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return true
+func (h *MovieHandler) handleError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err == nil {
+		return false
 	}
-	return false
+	writeError(w, r, h.logger, err)
+	return true
 }
 
-func (h *MovieHandler) parseID(w http.ResponseWriter, idStr string) (int, bool) {
+// parseID reads a positive numeric ID from a path segment or query
+// parameter, reporting field on failure.
+func (h *MovieHandler) parseID(w http.ResponseWriter, r *http.Request, field, idStr string) (int, bool) {
 	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		h.logger.Error("Invalid ID format", err)
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+	if err != nil || id <= 0 {
+		writeInvalidField(w, r, field, "Invalid ID")
 		return 0, false
 	}
 	return id, true
 }
 
+// parseInclude reads the comma-separated include parameter of list
+// endpoints, e.g. ?include=genres,cast.
+func parseInclude(r *http.Request) []repository.MovieRelation {
+	var include []repository.MovieRelation
+	for _, name := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			relation := repository.MovieRelation(name)
+			if !slices.Contains(include, relation) {
+				include = append(include, relation)
+			}
+		}
+	}
+	return include
+}
+
 func (h *MovieHandler) GetTopMovies(w http.ResponseWriter, r *http.Request) {
-	output, err := h.getTopMoviesUC.Execute()
-	if h.handleError(w, err, "Failed to get top movies") {
+	input := movie.GetTopMoviesInput{Include: parseInclude(r)}
+	output, err := h.getTopMoviesUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 	h.writeJSONResponse(w, output.Movies)
 }
 
 func (h *MovieHandler) GetRandomMovies(w http.ResponseWriter, r *http.Request) {
-	output, err := h.getRandomMoviesUC.Execute()
-	if h.handleError(w, err, "Failed to get random movies") {
+	input := movie.GetRandomMoviesInput{Include: parseInclude(r)}
+	output, err := h.getRandomMoviesUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 	h.writeJSONResponse(w, output.Movies)
@@ -91,7 +106,7 @@ func (h *MovieHandler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 
 	var genre *int
 	if genreStr != "" {
-		genreInt, ok := h.parseID(w, genreStr)
+		genreInt, ok := h.parseID(w, r, "genre", genreStr)
 		if !ok {
 			return
 		}
@@ -99,37 +114,36 @@ func (h *MovieHandler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input := movie.SearchMoviesInput{
-		Query: query,
-		Order: order,
-		Genre: genre,
+		Query:   query,
+		Order:   order,
+		Genre:   genre,
+		Include: parseInclude(r),
 	}
 
-	output, err := h.searchMoviesUC.Execute(input)
-	if err != nil {
-		h.writeJSONResponse(w, []interface{}{})
+	output, err := h.searchMoviesUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 	h.writeJSONResponse(w, output.Movies)
 }
 
 func (h *MovieHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/api/movies/"):]
-	id, ok := h.parseID(w, idStr)
+	id, ok := h.parseID(w, r, "id", r.PathValue("id"))
 	if !ok {
 		return
 	}
 
 	input := movie.GetMovieByIDInput{ID: id}
-	output, err := h.getMovieByIDUC.Execute(input)
-	if h.handleError(w, err, "Failed to get movie by ID") {
+	output, err := h.getMovieByIDUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 	h.writeJSONResponse(w, output.Movie)
 }
 
 func (h *MovieHandler) GetGenres(w http.ResponseWriter, r *http.Request) {
-	output, err := h.getGenresUC.Execute()
-	if h.handleError(w, err, "Failed to get genres") {
+	output, err := h.getGenresUC.Execute(r.Context())
+	if h.handleError(w, r, err) {
 		return
 	}
 	h.writeJSONResponse(w, output.Genres)
@@ -143,14 +157,13 @@ func (h *MovieHandler) GetRecommendations(w http.ResponseWriter, r *http.Request
 
 	email, ok := r.Context().Value(emailContextKey).(string)
 	if !ok {
-		h.writeJSONResponse(w, []interface{}{})
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
 		return
 	}
 
 	input := movie.GetRecommendationsInput{Email: email}
-	output, err := h.getRecommendationsUC.Execute(input)
-	if err != nil {
-		h.writeJSONResponse(w, []interface{}{})
+	output, err := h.getRecommendationsUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
 		return
 	}
 	h.writeJSONResponse(w, output.Movies)
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses.
+const ProblemContentType = "application/problem+json"
+
+// Stable error codes clients can switch on. Domain errors get their own
+// codes in errorMappings.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidBody      = "invalid_body"
+	CodeUnauthorized     = "unauthorized"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInternal         = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details object. Code, Errors and
+// RequestID are extension members.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes one invalid field of a validation problem.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+	// field marks a validation error on that request field.
+	field string
+}
+
+// errorMappings is the one place domain errors are turned into HTTP
+// statuses and error codes. Errors with a field are reported as field
+// errors of a validation_failed problem.
+var errorMappings = []errorMapping{
+	{err: repository.ErrMovieNotFound, status: http.StatusNotFound, code: "movie_not_found"},
+	{err: repository.ErrInvalidMovieID, status: http.StatusBadRequest, code: "invalid", field: "movie_id"},
+	{err: repository.ErrUserNotFound, status: http.StatusNotFound, code: "user_not_found"},
+	{err: repository.ErrUserAlreadyExists, status: http.StatusConflict, code: "user_already_exists"},
+	{err: repository.ErrAuthenticationValidation, status: http.StatusUnauthorized, code: "invalid_credentials"},
+	{err: repository.ErrRegistrationValidation, status: http.StatusBadRequest, code: "registration_failed"},
+	{err: repository.ErrNameRequired, status: http.StatusBadRequest, code: "required", field: "name"},
+	{err: repository.ErrMovieAlreadyInFavorites, status: http.StatusConflict, code: "already_in_collection"},
+	{err: repository.ErrMovieAlreadyInWatchlist, status: http.StatusConflict, code: "already_in_collection"},
+	{err: repository.ErrMovieNotInFavorites, status: http.StatusNotFound, code: "not_in_collection"},
+	{err: repository.ErrMovieNotInWatchlist, status: http.StatusNotFound, code: "not_in_collection"},
+	{err: repository.ErrInvalidCollectionType, status: http.StatusBadRequest, code: "invalid", field: "collection"},
+	{err: repository.ErrRecommendationsNotFound, status: http.StatusNotFound, code: "recommendations_not_found"},
+	{err: repository.ErrEmbeddingNotFound, status: http.StatusNotFound, code: "embedding_not_found"},
+	{err: repository.ErrCannotFollowSelf, status: http.StatusBadRequest, code: "cannot_follow_self"},
+	{err: repository.ErrInvalidActivityType, status: http.StatusBadRequest, code: "invalid", field: "event_type"},
+	{err: repository.ErrTooManyImportRows, status: http.StatusRequestEntityTooLarge, code: "too_many_import_rows"},
+	{err: repository.ErrFeedTokenNotFound, status: http.StatusNotFound, code: "feed_not_found"},
+	{err: repository.ErrInvalidFeedKind, status: http.StatusNotFound, code: "feed_not_found"},
+	{err: repository.ErrSitemapNotFound, status: http.StatusNotFound, code: "sitemap_not_found"},
+	{err: repository.ErrInvalidStatsYear, status: http.StatusBadRequest, code: "invalid", field: "year"},
+	{err: repository.ErrSearchQueryRequired, status: http.StatusBadRequest, code: "required", field: "q"},
+	{err: repository.ErrInvalidMovieRelation, status: http.StatusBadRequest, code: "invalid", field: "include"},
+	{err: valueobject.ErrEmptyEmail, status: http.StatusBadRequest, code: "required", field: "email"},
+	{err: valueobject.ErrInvalidEmail, status: http.StatusBadRequest, code: "invalid_format", field: "email"},
+	{err: valueobject.ErrEmptyPassword, status: http.StatusBadRequest, code: "required", field: "password"},
+	{err: valueobject.ErrPasswordTooShort, status: http.StatusBadRequest, code: "too_short", field: "password"},
+	{err: valueobject.ErrPasswordMismatch, status: http.StatusUnauthorized, code: "invalid_credentials"},
+}
+
+func lookupError(err error) (errorMapping, bool) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// problemFor maps err to a problem. Errors joined with errors.Join that are
+// all field errors become a single validation problem listing each field.
+func problemFor(err error) (Problem, bool) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	var fields []FieldError
+	for _, e := range errs {
+		m, ok := lookupError(e)
+		if !ok || m.field == "" {
+			fields = nil
+			break
+		}
+		fields = append(fields, FieldError{Field: m.field, Code: m.code, Message: e.Error()})
+	}
+	if len(fields) > 0 {
+		return validationProblem(fields...), true
+	}
+
+	m, ok := lookupError(err)
+	if !ok {
+		return Problem{}, false
+	}
+	if m.field != "" {
+		return validationProblem(FieldError{Field: m.field, Code: m.code, Message: m.err.Error()}), true
+	}
+	return newProblem(m.status, m.code, capitalize(m.err.Error())), true
+}
+
+func newProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func validationProblem(fields ...FieldError) Problem {
+	p := newProblem(http.StatusBadRequest, CodeValidationFailed, "The request has invalid fields")
+	p.Errors = fields
+	return p
+}
+
+// sendProblem writes p as application/problem+json, tagged with the
+// request path and the request ID set by RequestLogging.
+func sendProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID = w.Header().Get(RequestIDHeader)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// writeProblem reports an error detected by the handler itself.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	sendProblem(w, r, newProblem(status, code, detail))
+}
+
+// writeInvalidField reports a single malformed query or path parameter.
+func writeInvalidField(w http.ResponseWriter, r *http.Request, field, message string) {
+	sendProblem(w, r, validationProblem(FieldError{Field: field, Code: "invalid", Message: message}))
+}
+
+// writeUnauthorized rejects a request without valid bearer credentials.
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, detail string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+// writeMethodNotAllowed answers a method the route does not support.
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request, allow string) {
+	w.Header().Set("Allow", allow)
+	writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method "+r.Method+" is not allowed")
+}
+
+// writeError reports an error returned by a use case. Unmapped errors are
+// logged and hidden behind a generic 500.
+func writeError(w http.ResponseWriter, r *http.Request, log *logger.Logger, err error) {
+	p, ok := problemFor(err)
+	if !ok {
+		requestLogger(r, log).Error("Handler error", err)
+		p = newProblem(http.StatusInternalServerError, CodeInternal, "An unexpected error occurred")
+	}
+	sendProblem(w, r, p)
+}
+
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+func TestInvalidMovieIDIsAFieldError(t *testing.T) {
+	log := testLogger(t)
+	movies := NewMovieHandler(testCatalogue(3), nil, log)
+	// The use case rejects the ID before any repository is used
+	account := NewAccountHandler(nil, nil, nil, nil, config.AuthConfig{JWTSecret: "secret"}, config.RecommendationsConfig{}, nil, log)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/movies/{id}", movies.GetMovie)
+	mux.HandleFunc("POST /api/v1/account/save-to-collection", func(w http.ResponseWriter, r *http.Request) {
+		account.SaveToCollection(w, r.WithContext(context.WithValue(r.Context(), emailContextKey, "user@example.com")))
+	})
+
+	tests := []struct {
+		method, path, body string
+		wantField          string
+	}{
+		{http.MethodGet, "/api/v1/movies/0", "", "id"},
+		{http.MethodGet, "/api/v1/movies/-3", "", "id"},
+		{http.MethodPost, "/api/v1/account/save-to-collection", `{"movie_id":0,"collection":"favorite"}`, "movie_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body)
+			}
+			var problem Problem
+			if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != CodeValidationFailed || len(problem.Errors) != 1 ||
+				problem.Errors[0].Field != tt.wantField || problem.Errors[0].Code != "invalid" {
+				t.Errorf("problem = %+v, want an invalid %s", problem, tt.wantField)
+			}
+		})
+	}
+}
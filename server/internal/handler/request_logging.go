@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// RequestIDHeader carries the correlation ID between clients, proxies and
+// this service.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs accepted from clients so they cannot bloat
+// every log line of the request.
+const maxRequestIDLength = 128
+
+// RequestLogging takes the request ID from X-Request-ID (or generates one),
+// echoes it in the response, stores a logger carrying it (and the trace ID,
+// when the request is traced) in the request context and logs one line per
+// request once it completes. The line names the mux route the request
+// matched and its path, with feed tokens masked.
+func RequestLogging(log *logger.Logger, mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			reqLogger := log.With("request_id", requestID)
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				reqLogger = reqLogger.With("trace_id", sc.TraceID().String())
+			}
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+
+			next.ServeHTTP(rec, r.WithContext(logger.NewContext(r.Context(), reqLogger)))
+
+			args := []any{
+				"method", r.Method,
+				"route", routeOf(mux, r),
+				"path", redactedPath(mux, r),
+				"status", rec.status,
+				"bytes", rec.bytes,
+				"duration_ms", time.Since(start).Milliseconds(),
+			}
+			if rec.status >= http.StatusInternalServerError {
+				reqLogger.Warn("Request failed", args...)
+			} else {
+				reqLogger.Info("Request completed", args...)
+			}
+		})
+	}
+}
+
+// requestLogger returns the request-scoped logger set by RequestLogging.
+func requestLogger(r *http.Request, fallback *logger.Logger) *logger.Logger {
+	return logger.FromContext(r.Context(), fallback)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder captures the status code and body size written by the
+// wrapped handler. It forwards Flush so streamed responses keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+const testFeedToken = "f3c1b2a4d5e6f708192a3b4c5d6e7f80"
+
+// feedMux routes feeds like the API, recording the token each request was
+// served with.
+func feedMux(served *[]string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /feeds/{token}/{file}", func(w http.ResponseWriter, r *http.Request) {
+		*served = append(*served, r.PathValue("token"))
+		w.Write([]byte("<rss></rss>"))
+	})
+	return mux
+}
+
+func TestRequestLoggingMasksFeedToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	log, err := logger.New(logger.Options{Level: "info", Outputs: []string{logger.OutputFile}, FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var served []string
+	mux := feedMux(&served)
+	rec := httptest.NewRecorder()
+	RequestLogging(log, mux)(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/"+testFeedToken+"/recommendations.rss", nil))
+	log.Close()
+
+	if len(served) != 1 || served[0] != testFeedToken {
+		t.Fatalf("feed served with tokens %q", served)
+	}
+	line, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(line), testFeedToken) {
+		t.Errorf("access log contains the feed token: %s", line)
+	}
+	for _, want := range []string{`"route":"/feeds/{token}/{file}"`, `"path":"/feeds/[REDACTED]/recommendations.rss"`} {
+		if !strings.Contains(string(line), want) {
+			t.Errorf("access log is missing %s: %s", want, line)
+		}
+	}
+}
+
+func TestRequestTracingMasksFeedToken(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var served []string
+	mux := feedMux(&served)
+	rec := httptest.NewRecorder()
+	RequestTracing(mux)(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/"+testFeedToken+"/recommendations.ics", nil))
+
+	if len(served) != 1 || served[0] != testFeedToken {
+		t.Fatalf("feed served with tokens %q", served)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if name := spans[0].Name(); name != "GET /feeds/{token}/{file}" {
+		t.Errorf("span name = %q", name)
+	}
+	var urlPath string
+	for _, attr := range spans[0].Attributes() {
+		if strings.Contains(attr.Value.Emit(), testFeedToken) {
+			t.Errorf("span attribute %s contains the feed token", attr.Key)
+		}
+		if attr.Key == "url.path" {
+			urlPath = attr.Value.AsString()
+		}
+	}
+	if urlPath != "/feeds/[REDACTED]/recommendations.ics" {
+		t.Errorf("url.path = %q", urlPath)
+	}
+}
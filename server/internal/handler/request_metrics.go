@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/metrics"
+)
+
+// RequestMetrics records request counts and latency per route. The route is
+// the mux pattern the request matches, so /api/movies/7 and /api/movies/42
+// share one series instead of creating one per ID.
+func RequestMetrics(m *metrics.Metrics, mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routeOf(mux, r)
+
+			m.RequestStarted()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+
+			next.ServeHTTP(rec, r)
+
+			m.RequestFinished(route, r.Method, rec.status, time.Since(start))
+		})
+	}
+}
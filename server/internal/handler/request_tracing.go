@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestTracing starts a server span per request, continuing any trace the
+// caller propagated in the traceparent header. Spans are named after the mux
+// pattern the request matches, like RequestMetrics' route label, and record
+// the path with feed tokens masked.
+func RequestTracing(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		masked := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Overwrites the url.path otelhttp recorded from the raw request
+			trace.SpanFromContext(r.Context()).SetAttributes(semconv.URLPath(redactedPath(mux, r)))
+			next.ServeHTTP(w, r)
+		})
+		return otelhttp.NewHandler(masked, "http.request",
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + routeOf(mux, r)
+			}),
+		)
+	}
+}
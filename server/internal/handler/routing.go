@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+)
+
+// probeMethods are the methods APIFallback tries when working out which
+// ones a path supports.
+var probeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// routeOf returns the path part of the mux pattern r matches, so that
+// "GET /api/v1/movies/{id}" and a prefix pattern like "/assets/" are both
+// reported without their method, or "unmatched".
+func routeOf(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if pattern == "" {
+		return "unmatched"
+	}
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	return pattern
+}
+
+// redactedPath returns r's path with the segments a {token} wildcard of its
+// mux pattern matches masked, so feed paths can be logged and traced
+// without the secret that authenticates them.
+func redactedPath(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	if !strings.Contains(pattern, "{token}") {
+		return r.URL.Path
+	}
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(r.URL.Path, "/")
+	for i, segment := range patternSegments {
+		if segment == "{token}" && i < len(pathSegments) {
+			pathSegments[i] = "[REDACTED]"
+		}
+	}
+	return strings.Join(pathSegments, "/")
+}
+
+// APIFallback answers the requests under prefix that no API route takes:
+// 405 with an Allow header when the path exists for other methods, 404
+// otherwise, both as problem details. Register it on mux as the subtree
+// pattern prefix, e.g. "/api/".
+func APIFallback(mux *http.ServeMux, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, method := range probeMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != prefix {
+				allowed = append(allowed, method)
+			}
+		}
+		if len(allowed) > 0 {
+			writeMethodNotAllowed(w, r, strings.Join(allowed, ", "))
+			return
+		}
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, "No API route matches "+r.URL.Path)
+	})
+}
+
+// Deprecated marks responses of an old API path as deprecated and links to
+// its successor, built by replacing oldPrefix with newPrefix in the request
+// path (trailing slashes dropped).
+func Deprecated(oldPrefix, newPrefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := newPrefix + strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, oldPrefix), "/")
+		w.Header().Set("Deprecation", "true")
+		w.Header().Add("Link", "<"+successor+">; rel=\"successor-version\"")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+// schema.org JSON-LD describing the server-rendered pages to search
+// engines. Only the properties we have data for are set.
+
+const schemaContext = "https://schema.org"
+
+type ldWebSite struct {
+	Context         string         `json:"@context"`
+	Type            string         `json:"@type"`
+	Name            string         `json:"name"`
+	URL             string         `json:"url"`
+	PotentialAction ldSearchAction `json:"potentialAction"`
+}
+
+type ldSearchAction struct {
+	Type       string `json:"@type"`
+	Target     string `json:"target"`
+	QueryInput string `json:"query-input"`
+}
+
+type ldMovie struct {
+	Context         string             `json:"@context"`
+	Type            string             `json:"@type"`
+	URL             string             `json:"url"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description,omitempty"`
+	Image           string             `json:"image,omitempty"`
+	DateCreated     string             `json:"dateCreated,omitempty"`
+	InLanguage      string             `json:"inLanguage,omitempty"`
+	Genre           []string           `json:"genre,omitempty"`
+	Keywords        string             `json:"keywords,omitempty"`
+	Actor           []ldPerson         `json:"actor,omitempty"`
+	AggregateRating *ldAggregateRating `json:"aggregateRating,omitempty"`
+	Trailer         *ldVideo           `json:"trailer,omitempty"`
+}
+
+// ldPerson is an actor within a movie. There are no actor pages, so a
+// person has no url or @id of their own.
+type ldPerson struct {
+	Type  string `json:"@type"`
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+}
+
+type ldAggregateRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float32 `json:"ratingValue"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+}
+
+type ldVideo struct {
+	Type         string `json:"@type"`
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	EmbedURL     string `json:"embedUrl,omitempty"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+}
+
+type ldItemList struct {
+	Context         string       `json:"@context"`
+	Type            string       `json:"@type"`
+	URL             string       `json:"url"`
+	Name            string       `json:"name,omitempty"`
+	NumberOfItems   int          `json:"numberOfItems"`
+	ItemListElement []ldListItem `json:"itemListElement"`
+}
+
+type ldListItem struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	URL      string `json:"url"`
+	Name     string `json:"name"`
+}
+
+// websiteStructuredData describes the site and its search, so results can
+// show a search box.
+func websiteStructuredData(base string) ldWebSite {
+	return ldWebSite{
+		Context: schemaContext,
+		Type:    "WebSite",
+		Name:    "Moovies",
+		URL:     base + "/",
+		PotentialAction: ldSearchAction{
+			Type:       "SearchAction",
+			Target:     base + "/movies?q={search_term_string}",
+			QueryInput: "required name=search_term_string",
+		},
+	}
+}
+
+func movieStructuredData(m *models.Movie, base string) ldMovie {
+	ld := ldMovie{
+		Context:     schemaContext,
+		Type:        "Movie",
+		URL:         movieURL(base, m.ID),
+		Name:        m.Title,
+		Description: stringValue(m.Overview),
+		Image:       absoluteURL(base, stringValue(m.PosterURL)),
+		InLanguage:  stringValue(m.Language),
+		Keywords:    strings.Join(m.Keywords, ", "),
+	}
+	if m.ReleaseYear > 0 {
+		ld.DateCreated = strconv.Itoa(m.ReleaseYear)
+	}
+	for _, genre := range m.Genres {
+		ld.Genre = append(ld.Genre, genre.Name)
+	}
+	for _, actor := range m.Casting {
+		ld.Actor = append(ld.Actor, ldPerson{
+			Type:  "Person",
+			Name:  strings.TrimSpace(actor.FirstName + " " + actor.LastName),
+			Image: absoluteURL(base, stringValue(actor.ImageURL)),
+		})
+	}
+	if m.Score != nil {
+		ld.AggregateRating = &ldAggregateRating{
+			Type:        "AggregateRating",
+			RatingValue: *m.Score,
+			BestRating:  10,
+			WorstRating: 0,
+		}
+	}
+	if trailer := stringValue(m.TrailerURL); trailer != "" {
+		ld.Trailer = &ldVideo{
+			Type:         "VideoObject",
+			Name:         m.Title + " trailer",
+			URL:          trailer,
+			EmbedURL:     youtubeEmbedURL(trailer),
+			ThumbnailURL: ld.Image,
+		}
+	}
+	return ld
+}
+
+// itemListStructuredData lists movies in the order the page shows them.
+func itemListStructuredData(movies []models.Movie, name, canonical, base string) ldItemList {
+	ld := ldItemList{
+		Context:         schemaContext,
+		Type:            "ItemList",
+		URL:             canonical,
+		Name:            name,
+		NumberOfItems:   len(movies),
+		ItemListElement: make([]ldListItem, 0, len(movies)),
+	}
+	for i, m := range movies {
+		ld.ItemListElement = append(ld.ItemListElement, ldListItem{
+			Type:     "ListItem",
+			Position: i + 1,
+			URL:      movieURL(base, m.ID),
+			Name:     m.Title,
+		})
+	}
+	return ld
+}
+
+func movieURL(base string, id int) string {
+	return base + "/movies/" + strconv.Itoa(id)
+}
+
+// absoluteURL resolves a site-relative URL such as /images/x.jpg against
+// base; absolute URLs and "" are returned unchanged.
+func absoluteURL(base, ref string) string {
+	if strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, "//") {
+		return base + ref
+	}
+	return ref
+}
+
+// youtubeEmbedURL turns a youtube.com/watch?v= link into its embed URL, the
+// way the youtube-embed component does, or returns "".
+func youtubeEmbedURL(trailer string) string {
+	u, err := url.Parse(trailer)
+	if err != nil {
+		return ""
+	}
+	if id := u.Query().Get("v"); id != "" {
+		return "https://www.youtube.com/embed/" + url.PathEscape(id)
+	}
+	return ""
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/infrastructure/cache"
+	"github.com/jgamaraalv/movies.git/internal/usecase/sitemap"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	// sitemapKeyPrefix namespaces the generated sitemaps in the cache.
+	sitemapKeyPrefix = "sitemap:"
+	pagesSitemap     = "pages.xml"
+)
+
+// SitemapHandler serves /robots.txt, the sitemap index at /sitemap.xml and
+// the sitemaps it lists under /sitemaps/: pages.xml with the home and genre
+// pages, and movies-N.xml with every movie page, split into files of at
+// most URLsPerFile URLs. Every URL starts with the configured public URL.
+// A sitemap is streamed from Postgres to the client that asked for it,
+// keeping a copy in the cache that is served until CacheTTL passes or a
+// catalogue import commits.
+type SitemapHandler struct {
+	getIndexUC     *sitemap.GetSitemapIndexUseCase
+	streamMoviesUC *sitemap.StreamMovieSitemapUseCase
+	getGenresUC    *sitemap.GetGenreSitemapUseCase
+	cache          cache.Backend
+	publicURL      string
+	cfg            config.SitemapConfig
+	cacheControl   string
+	flights        singleflight.Group
+	logger         *logger.Logger
+}
+
+// NewSitemapHandler lists the pages under publicURL and sends the sitemaps
+// with the Cache-Control of policy.
+func NewSitemapHandler(repo repository.SitemapRepository, backend cache.Backend, publicURL string, cfg config.SitemapConfig, policy config.CachePolicy, log *logger.Logger) *SitemapHandler {
+	return &SitemapHandler{
+		getIndexUC:     sitemap.NewGetSitemapIndexUseCase(repo, log),
+		streamMoviesUC: sitemap.NewStreamMovieSitemapUseCase(repo, log),
+		getGenresUC:    sitemap.NewGetGenreSitemapUseCase(repo, log),
+		cache:          backend,
+		publicURL:      publicURL,
+		cfg:            cfg,
+		cacheControl:   cacheControlFor(policy),
+		logger:         log,
+	}
+}
+
+// InvalidateCatalogue drops the cached sitemaps. The API calls it when a
+// catalogue import commits.
+func (h *SitemapHandler) InvalidateCatalogue(ctx context.Context) {
+	if err := h.cache.DeletePrefix(ctx, sitemapKeyPrefix); err != nil {
+		h.logger.Error("Failed to invalidate sitemap cache", err)
+	}
+}
+
+// Robots serves /robots.txt, keeping crawlers out of the private pages and
+// feeds and pointing them at the sitemap index.
+func (h *SitemapHandler) Robots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "User-agent: *\nDisallow: /account/\nDisallow: /feeds/\n\nSitemap: %s/sitemap.xml\n", h.publicURL)
+}
+
+// Index serves /sitemap.xml.
+func (h *SitemapHandler) Index(w http.ResponseWriter, r *http.Request) {
+	h.serveSitemap(w, r, "index.xml", h.writeIndex)
+}
+
+// ServeSitemap serves /sitemaps/pages.xml and /sitemaps/movies-N.xml,
+// numbered from 1.
+func (h *SitemapHandler) ServeSitemap(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	if file == pagesSitemap {
+		h.serveSitemap(w, r, file, h.writePages)
+		return
+	}
+
+	number, ok := strings.CutPrefix(file, "movies-")
+	if ok {
+		number, ok = strings.CutSuffix(number, ".xml")
+	}
+	page, err := strconv.Atoi(number)
+	if !ok || err != nil || page < 1 || strconv.Itoa(page) != number {
+		writeError(w, r, h.logger, repository.ErrSitemapNotFound)
+		return
+	}
+	h.serveSitemap(w, r, file, func(ctx context.Context, w io.Writer) error {
+		return h.writeMovies(ctx, w, page-1)
+	})
+}
+
+// serveSitemap serves the cached copy of the sitemap named file. On a miss
+// it streams the sitemap to the client while generate writes it, then
+// caches it. Concurrent misses share one generation: the first request
+// streams it and the others are sent the finished copy.
+func (h *SitemapHandler) serveSitemap(w http.ResponseWriter, r *http.Request, file string, generate func(ctx context.Context, w io.Writer) error) {
+	ctx := r.Context()
+	key := sitemapKeyPrefix + file
+
+	body, ok, err := h.cache.Get(ctx, key)
+	if err != nil {
+		requestLogger(r, h.logger).Warn("Sitemap cache read failed", "key", key, "error", err.Error())
+	}
+	if ok {
+		h.writeSitemap(w, r, body)
+		return
+	}
+
+	var stream *sitemapStream
+	result, err, _ := h.flights.Do(key, func() (any, error) {
+		stream = &sitemapStream{w: w, start: h.setHeaders}
+		// Shared by every request waiting on key, so the streaming client
+		// going away must not cancel it
+		genCtx := context.WithoutCancel(ctx)
+		if err := generate(genCtx, stream); err != nil {
+			return nil, err
+		}
+		if err := h.cache.Set(genCtx, key, stream.body.Bytes(), h.cfg.CacheTTL); err != nil {
+			h.logger.Warn("Sitemap cache write failed", "key", key, "error", err.Error())
+		}
+		return stream.body.Bytes(), nil
+	})
+	switch {
+	case err != nil && stream != nil && stream.started:
+		// The status is already sent; leave the document unclosed rather
+		// than ending it as if it were complete
+		requestLogger(r, h.logger).Error("Sitemap generation interrupted", err, "file", file)
+	case err != nil:
+		writeError(w, r, h.logger, err)
+	case stream == nil:
+		// Another request generated it
+		h.writeSitemap(w, r, result.([]byte))
+	}
+}
+
+// writeSitemap sends a generated sitemap, or 304 when the client's copy is
+// current.
+func (h *SitemapHandler) writeSitemap(w http.ResponseWriter, r *http.Request, body []byte) {
+	etag := contentETag(body, "")
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("Cache-Control", h.cacheControl)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.setHeaders(w)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Write(body)
+}
+
+func (h *SitemapHandler) setHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", h.cacheControl)
+}
+
+// sitemapStream sends a sitemap to the client as it is written and keeps a
+// copy of it. Nothing is sent until the first write, so a sitemap that
+// fails before then can still be answered with an error. A client that
+// goes away stops receiving, not the copy.
+type sitemapStream struct {
+	w       http.ResponseWriter
+	start   func(http.ResponseWriter)
+	started bool
+	body    bytes.Buffer
+	err     error
+}
+
+func (s *sitemapStream) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.start(s.w)
+	}
+	s.body.Write(p)
+	if s.err == nil {
+		_, s.err = s.w.Write(p)
+	}
+	return len(p), nil
+}
+
+type sitemapIndexEntry struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+type sitemapURL struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+func (h *SitemapHandler) writeIndex(ctx context.Context, w io.Writer) error {
+	output, err := h.getIndexUC.Execute(ctx, sitemap.GetSitemapIndexInput{PageSize: h.cfg.URLsPerFile})
+	if err != nil {
+		return err
+	}
+
+	return writeSitemapXML(w, "sitemapindex", func(enc *xml.Encoder) error {
+		err := enc.Encode(sitemapIndexEntry{
+			Loc:     h.publicURL + "/sitemaps/" + pagesSitemap,
+			LastMod: sitemapTime(output.LastModified),
+		})
+		if err != nil {
+			return err
+		}
+		for i, lastModified := range output.MoviePages {
+			err := enc.Encode(sitemapIndexEntry{
+				Loc:     h.publicURL + "/sitemaps/movies-" + strconv.Itoa(i+1) + ".xml",
+				LastMod: sitemapTime(lastModified),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writePages lists the home page and a page per genre. There are no actor
+// pages to list: actors only appear on the pages of their movies.
+func (h *SitemapHandler) writePages(ctx context.Context, w io.Writer) error {
+	output, err := h.getGenresUC.Execute(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeSitemapXML(w, "urlset", func(enc *xml.Encoder) error {
+		if err := enc.Encode(sitemapURL{Loc: h.publicURL + "/"}); err != nil {
+			return err
+		}
+		for _, genre := range output.Genres {
+			err := enc.Encode(sitemapURL{
+				Loc:     h.publicURL + "/movies?genre=" + strconv.Itoa(genre.ID),
+				LastMod: sitemapTime(genre.LastModified),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeMovies encodes each movie of the zero-based page as its row is read,
+// so a full page is never held in memory as anything but XML.
+func (h *SitemapHandler) writeMovies(ctx context.Context, w io.Writer, page int) error {
+	return writeSitemapXML(w, "urlset", func(enc *xml.Encoder) error {
+		_, err := h.streamMoviesUC.Execute(ctx, sitemap.StreamMovieSitemapInput{
+			Page:     page,
+			PageSize: h.cfg.URLsPerFile,
+			Write: func(entry models.SitemapEntry) error {
+				return enc.Encode(sitemapURL{
+					Loc:     movieURL(h.publicURL, entry.ID),
+					LastMod: sitemapTime(entry.LastModified),
+				})
+			},
+		})
+		return err
+	})
+}
+
+// writeSitemapXML wraps what body encodes in the root element of a sitemap
+// document. The encoder only flushes when body encodes an entry, so a body
+// failing before its first entry leaves w untouched.
+func writeSitemapXML(w io.Writer, root string, body func(*xml.Encoder) error) error {
+	enc := xml.NewEncoder(&declarationWriter{w: w})
+	enc.Indent("", "  ")
+	start := xml.StartElement{
+		Name: xml.Name{Local: root},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: sitemapNamespace}},
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := body(enc); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// declarationWriter writes the XML declaration ahead of the first write.
+type declarationWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (d *declarationWriter) Write(p []byte) (int, error) {
+	if !d.written {
+		d.written = true
+		if _, err := io.WriteString(d.w, xml.Header); err != nil {
+			return 0, err
+		}
+	}
+	return d.w.Write(p)
+}
+
+// sitemapTime formats t in the W3C Datetime form sitemaps use, or returns
+// "" for the zero time so lastmod is left out.
+func sitemapTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/infrastructure/cache"
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+func newTestSitemapHandler(t *testing.T, movies int) (*SitemapHandler, *fakeSitemapRepository) {
+	t.Helper()
+	changed := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeSitemapRepository{genres: []models.SitemapEntry{{ID: 18, LastModified: changed}}}
+	for id := 1; id <= movies; id++ {
+		repo.movies = append(repo.movies, models.SitemapEntry{ID: id, LastModified: changed})
+	}
+	cfg := config.SitemapConfig{URLsPerFile: 2, CacheTTL: time.Hour}
+	policy := config.CachePolicy{MaxAge: time.Hour}
+	return NewSitemapHandler(repo, cache.NewMemory(10), testPublicURL, cfg, policy, testLogger(t)), repo
+}
+
+// getSitemap requests path on host, sending ifNoneMatch when set.
+func getSitemap(h *SitemapHandler, path, host, ifNoneMatch string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /robots.txt", h.Robots)
+	mux.HandleFunc("GET /sitemap.xml", h.Index)
+	mux.HandleFunc("GET /sitemaps/{file}", h.ServeSitemap)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSitemapsUsePublicURL(t *testing.T) {
+	h, _ := newTestSitemapHandler(t, 3)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/robots.txt", []string{"Sitemap: " + testPublicURL + "/sitemap.xml"}},
+		{"/sitemap.xml", []string{
+			"<loc>" + testPublicURL + "/sitemaps/pages.xml</loc>",
+			"<loc>" + testPublicURL + "/sitemaps/movies-2.xml</loc>",
+		}},
+		{"/sitemaps/pages.xml", []string{
+			"<loc>" + testPublicURL + "/</loc>",
+			"<loc>" + testPublicURL + "/movies?genre=18</loc>",
+		}},
+		{"/sitemaps/movies-2.xml", []string{
+			`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+			"<loc>" + testPublicURL + "/movies/3</loc>",
+			"<lastmod>2026-01-01T12:00:00Z</lastmod>",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := getSitemap(h, tt.path, "attacker.example", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			body := rec.Body.String()
+			if strings.Contains(body, "attacker.example") {
+				t.Error("sitemap lists the request's Host")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body is missing %s:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSitemapCachedByFile(t *testing.T) {
+	h, repo := newTestSitemapHandler(t, 3)
+
+	// A miss streams the sitemap, so its ETag is not known yet
+	streamed := getSitemap(h, "/sitemaps/movies-1.xml", "movies.example.com", "")
+	if streamed.Code != http.StatusOK || streamed.Header().Get("ETag") != "" {
+		t.Fatalf("miss: status = %d, ETag = %q", streamed.Code, streamed.Header().Get("ETag"))
+	}
+	if got := streamed.Header().Get("Cache-Control"); got != "public, max-age=3600" {
+		t.Errorf("miss: Cache-Control = %q", got)
+	}
+
+	// Every host gets the same copy
+	cached := getSitemap(h, "/sitemaps/movies-1.xml", "other.example", "")
+	if n := repo.streamed.Load(); n != 1 {
+		t.Errorf("2 requests streamed the page %d times, want 1", n)
+	}
+	if cached.Body.String() != streamed.Body.String() {
+		t.Error("cached copy differs from the streamed one")
+	}
+	etag := cached.Header().Get("ETag")
+	if etag == "" || cached.Header().Get("Content-Length") == "" {
+		t.Fatalf("hit: ETag = %q, Content-Length = %q", etag, cached.Header().Get("Content-Length"))
+	}
+
+	notModified := getSitemap(h, "/sitemaps/movies-1.xml", "movies.example.com", etag)
+	if notModified.Code != http.StatusNotModified || notModified.Body.Len() != 0 {
+		t.Errorf("revalidation: status = %d with %d body bytes, want 304", notModified.Code, notModified.Body.Len())
+	}
+
+	h.InvalidateCatalogue(t.Context())
+	getSitemap(h, "/sitemaps/movies-1.xml", "movies.example.com", "")
+	if n := repo.streamed.Load(); n != 2 {
+		t.Errorf("request after invalidation streamed the page %d times in total, want 2", n)
+	}
+}
+
+func TestSitemapPastLastPage(t *testing.T) {
+	h, repo := newTestSitemapHandler(t, 3)
+
+	for _, path := range []string{"/sitemaps/movies-3.xml", "/sitemaps/movies-0.xml", "/sitemaps/movies-01.xml", "/sitemaps/actors.xml"} {
+		rec := getSitemap(h, path, "movies.example.com", "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+			t.Errorf("%s: Content-Type = %q, want a problem", path, ct)
+		}
+	}
+
+	// Errors are not cached
+	getSitemap(h, "/sitemaps/movies-3.xml", "movies.example.com", "")
+	if n := repo.streamed.Load(); n != 2 {
+		t.Errorf("2 requests for an empty page streamed %d times, want 2", n)
+	}
+}
+
+func TestSitemapConcurrentMissesShareOneStream(t *testing.T) {
+	h, repo := newTestSitemapHandler(t, 3)
+	repo.release = make(chan struct{})
+
+	responses := make(chan *httptest.ResponseRecorder, 2)
+	go func() { responses <- getSitemap(h, "/sitemaps/movies-1.xml", "movies.example.com", "") }()
+
+	// Join the second request to the first one's generation
+	deadline := time.Now().Add(5 * time.Second)
+	for repo.streamed.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the first request never reached the repository")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go func() { responses <- getSitemap(h, "/sitemaps/movies-1.xml", "movies.example.com", "") }()
+	time.Sleep(20 * time.Millisecond)
+	close(repo.release)
+
+	first, second := <-responses, <-responses
+	if n := repo.streamed.Load(); n != 1 {
+		t.Errorf("2 concurrent misses streamed the page %d times, want 1", n)
+	}
+	if first.Code != http.StatusOK || second.Code != http.StatusOK || first.Body.String() != second.Body.String() {
+		t.Errorf("responses differ: %d %q and %d %q", first.Code, first.Body, second.Code, second.Body)
+	}
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	socialuc "github.com/jgamaraalv/movies.git/internal/usecase/social"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type FollowResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type FeedResponse struct {
+	Events     []models.ActivityEvent `json:"events"`
+	NextCursor int64                  `json:"next_cursor,omitempty"`
+}
+
+type SocialHandler struct {
+	followUserUC   *socialuc.FollowUserUseCase
+	unfollowUserUC *socialuc.UnfollowUserUseCase
+	getFollowersUC *socialuc.GetFollowersUseCase
+	getFollowingUC *socialuc.GetFollowingUseCase
+	getFeedUC      *socialuc.GetFeedUseCase
+	logger         *logger.Logger
+}
+
+func NewSocialHandler(repo repository.SocialRepository, log *logger.Logger) *SocialHandler {
+	return &SocialHandler{
+		followUserUC:   socialuc.NewFollowUserUseCase(repo, log),
+		unfollowUserUC: socialuc.NewUnfollowUserUseCase(repo, log),
+		getFollowersUC: socialuc.NewGetFollowersUseCase(repo, log),
+		getFollowingUC: socialuc.NewGetFollowingUseCase(repo, log),
+		getFeedUC:      socialuc.NewGetFeedUseCase(repo, log),
+		logger:         log,
+	}
+}
+
+func (h *SocialHandler) writeJSONResponse(w http.ResponseWriter, data interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode response", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return err
+	}
+	return nil
+}
+
+func (h *SocialHandler) handleError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err == nil {
+		return false
+	}
+	writeError(w, r, h.logger, err)
+	return true
+}
+
+// queryInt reads an optional non-negative integer query parameter.
+func queryInt(r *http.Request, name string) (int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// pathUserID reads the {id} path parameter of the /users/{id}/... routes.
+func pathUserID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeInvalidField(w, r, "id", "Invalid ID")
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *SocialHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	input := socialuc.FollowUserInput{Email: email, FolloweeID: userID}
+	output, err := h.followUserUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	h.writeJSONResponse(w, FollowResponse{Success: output.Success, Message: output.Message})
+}
+
+func (h *SocialHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	input := socialuc.UnfollowUserInput{Email: email, FolloweeID: userID}
+	output, err := h.unfollowUserUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	h.writeJSONResponse(w, FollowResponse{Success: output.Success, Message: output.Message})
+}
+
+func (h *SocialHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, okLimit := queryInt(r, "limit")
+	offset, okOffset := queryInt(r, "offset")
+	if !okLimit || !okOffset {
+		writeProblem(w, r, http.StatusBadRequest, CodeValidationFailed, "Invalid pagination parameters")
+		return
+	}
+
+	input := socialuc.GetFollowersInput{UserID: userID, Limit: int(limit), Offset: int(offset)}
+	output, err := h.getFollowersUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	h.writeJSONResponse(w, output.Users)
+}
+
+func (h *SocialHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	limit, okLimit := queryInt(r, "limit")
+	offset, okOffset := queryInt(r, "offset")
+	if !okLimit || !okOffset {
+		writeProblem(w, r, http.StatusBadRequest, CodeValidationFailed, "Invalid pagination parameters")
+		return
+	}
+
+	input := socialuc.GetFollowingInput{UserID: userID, Limit: int(limit), Offset: int(offset)}
+	output, err := h.getFollowingUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	h.writeJSONResponse(w, output.Users)
+}
+
+func (h *SocialHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	before, okBefore := queryInt(r, "before")
+	limit, okLimit := queryInt(r, "limit")
+	if !okBefore || !okLimit {
+		writeProblem(w, r, http.StatusBadRequest, CodeValidationFailed, "Invalid pagination parameters")
+		return
+	}
+
+	input := socialuc.GetFeedInput{Email: email, Before: before, Limit: int(limit)}
+	output, err := h.getFeedUC.Execute(r.Context(), input)
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	h.writeJSONResponse(w, FeedResponse{Events: output.Events, NextCursor: output.NextCursor})
+}
@@ -1,16 +1,16 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
-	"html/template"
+	"bytes"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/jgamaraalv/movies.git/internal/config"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
+	"github.com/jgamaraalv/movies.git/internal/ssr"
 	"github.com/jgamaraalv/movies.git/internal/usecase/movie"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
@@ -18,27 +18,38 @@ import (
 
 type SSRHandler struct {
 	movieHandler *MovieHandler
+	renderer     *ssr.Renderer
+	publicURL    string
+	metrics      *metrics.Metrics
 	logger       *logger.Logger
-	publicDir    string
 }
 
-func NewSSRHandler(movieHandler *MovieHandler, log *logger.Logger) (*SSRHandler, error) {
-	publicDir := os.Getenv("PUBLIC_DIR")
-	if publicDir == "" {
-		publicDir = "public"
-		if _, err := os.Stat(publicDir); os.IsNotExist(err) {
-			publicDir = "../../public"
-		}
+// NewSSRHandler renders pages from index.html in publicDir, which the
+// configuration has already resolved to an absolute path, and the page
+// templates. Both are parsed once here, and again when they change if
+// cfg.Reload is set. Canonical links and structured data point at
+// publicURL, never at the Host a request was sent to.
+func NewSSRHandler(movieHandler *MovieHandler, publicDir, publicURL string, cfg config.SSRConfig, m *metrics.Metrics, log *logger.Logger) (*SSRHandler, error) {
+	renderer, err := ssr.NewRenderer(publicDir, cfg)
+	if err != nil {
+		return nil, err
 	}
-	publicDir, _ = filepath.Abs(publicDir)
-
 	return &SSRHandler{
 		movieHandler: movieHandler,
+		renderer:     renderer,
+		publicURL:    publicURL,
+		metrics:      m,
 		logger:       log,
-		publicDir:    publicDir,
 	}, nil
 }
 
+// serveIndex serves the unrendered index.html for the SPA to render.
+func (h *SSRHandler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index, modTime := h.renderer.Index()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeContent(w, r, "index.html", modTime, bytes.NewReader(index))
+}
+
 func (h *SSRHandler) isCrawler(r *http.Request) bool {
 	userAgent := strings.ToLower(r.Header.Get("User-Agent"))
 	crawlers := []string{
@@ -126,44 +137,63 @@ type PageData struct {
 	Query        string         `json:"query,omitempty"`
 	Order        string         `json:"order,omitempty"`
 	Genre        string         `json:"genre,omitempty"`
+	// Orders are the choices of the sort select; the frontend has its own.
+	Orders []SearchOrder `json:"-"`
+}
+
+// SearchOrder is one way search results can be sorted.
+type SearchOrder struct {
+	Value string
+	Label string
+}
+
+var searchOrders = []SearchOrder{
+	{"popularity", "Sort by Popularity"},
+	{"score", "Sort by Score"},
+	{"date", "Sort by Release Date"},
+	{"name", "Sort by Name"},
 }
 
 // HomePage renders the home page with SSR
 func (h *SSRHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	if !h.shouldUseSSR(r) {
 		// Serve SPA fallback
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		h.serveIndex(w, r)
 		return
 	}
 
 	// Fetch data for SSR
-	topMoviesOutput, err := h.movieHandler.getTopMoviesUC.Execute()
+	topMoviesOutput, err := h.movieHandler.getTopMoviesUC.Execute(r.Context(), movie.GetTopMoviesInput{})
 	if err != nil {
-		h.logger.Error("Failed to get top movies for SSR", err)
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		requestLogger(r, h.logger).Error("Failed to get top movies for SSR", err)
+		h.serveIndex(w, r)
 		return
 	}
 
-	randomMoviesOutput, err := h.movieHandler.getRandomMoviesUC.Execute()
+	randomMoviesOutput, err := h.movieHandler.getRandomMoviesUC.Execute(r.Context(), movie.GetRandomMoviesInput{})
 	if err != nil {
-		h.logger.Error("Failed to get random movies for SSR", err)
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		requestLogger(r, h.logger).Error("Failed to get random movies for SSR", err)
+		h.serveIndex(w, r)
 		return
 	}
 
 	// Render HTML with data
-	h.renderPage(w, "home", PageData{
+	base := h.publicURL
+	h.renderPage(w, r, http.StatusOK, "home", PageData{
 		Title:        "Movies - Discover Top Films",
 		Description:  "Discover the top movies and find something great to watch today",
 		TopMovies:    topMoviesOutput.Movies,
 		RandomMovies: randomMoviesOutput.Movies,
+	}, ssr.Meta{
+		Canonical:      base + "/",
+		StructuredData: websiteStructuredData(base),
 	})
 }
 
 // MovieDetailsPage renders movie details page with SSR
 func (h *SSRHandler) MovieDetailsPage(w http.ResponseWriter, r *http.Request) {
 	if !h.shouldUseSSR(r) {
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		h.serveIndex(w, r)
 		return
 	}
 
@@ -171,22 +201,22 @@ func (h *SSRHandler) MovieDetailsPage(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/movies/")
 	idStr := strings.TrimSuffix(path, "/")
 
-	id, ok := h.movieHandler.parseID(w, idStr)
-	if !ok {
-		http.NotFound(w, r)
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.notFoundPage(w, r)
 		return
 	}
 
 	// Fetch movie data
 	input := movie.GetMovieByIDInput{ID: id}
-	output, err := h.movieHandler.getMovieByIDUC.Execute(input)
+	output, err := h.movieHandler.getMovieByIDUC.Execute(r.Context(), input)
 	if err != nil {
-		if err == repository.ErrMovieNotFound {
-			http.NotFound(w, r)
+		if err == repository.ErrMovieNotFound || err == repository.ErrInvalidMovieID {
+			h.notFoundPage(w, r)
 			return
 		}
-		h.logger.Error("Failed to get movie for SSR", err)
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		requestLogger(r, h.logger).Error("Failed to get movie for SSR", err)
+		h.serveIndex(w, r)
 		return
 	}
 
@@ -201,17 +231,23 @@ func (h *SSRHandler) MovieDetailsPage(w http.ResponseWriter, r *http.Request) {
 		description = *movieData.Overview
 	}
 
-	h.renderPage(w, "movie-details", PageData{
+	base := h.publicURL
+	h.renderPage(w, r, http.StatusOK, "movie-details", PageData{
 		Title:       title,
 		Description: description,
 		Movie:       &movieData,
+	}, ssr.Meta{
+		Canonical:      movieURL(base, movieData.ID),
+		Image:          absoluteURL(base, stringValue(movieData.PosterURL)),
+		Type:           "video.movie",
+		StructuredData: movieStructuredData(&movieData, base),
 	})
 }
 
-// MoviesPage renders search results page with SSR
+// MoviesPage renders search results, or a genre's movies, with SSR
 func (h *SSRHandler) MoviesPage(w http.ResponseWriter, r *http.Request) {
 	if !h.shouldUseSSR(r) {
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		h.serveIndex(w, r)
 		return
 	}
 
@@ -221,18 +257,21 @@ func (h *SSRHandler) MoviesPage(w http.ResponseWriter, r *http.Request) {
 
 	var genre *int
 	if genreStr != "" {
-		genreInt, ok := h.movieHandler.parseID(w, genreStr)
-		if !ok {
-			http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		genreInt, err := strconv.Atoi(genreStr)
+		if err != nil {
+			h.serveIndex(w, r)
 			return
 		}
 		genre = &genreInt
 	}
 
 	// Fetch genres for filter
-	genresOutput, err := h.movieHandler.getGenresUC.Execute()
+	var genres []models.Genre
+	genresOutput, err := h.movieHandler.getGenresUC.Execute(r.Context())
 	if err != nil {
-		h.logger.Error("Failed to get genres for SSR", err)
+		requestLogger(r, h.logger).Error("Failed to get genres for SSR", err)
+	} else {
+		genres = genresOutput.Genres
 	}
 
 	// Search movies
@@ -242,240 +281,83 @@ func (h *SSRHandler) MoviesPage(w http.ResponseWriter, r *http.Request) {
 		Genre: genre,
 	}
 
-	searchOutput, err := h.movieHandler.searchMoviesUC.Execute(searchInput)
+	searchOutput, err := h.movieHandler.searchMoviesUC.Execute(r.Context(), searchInput)
 	if err != nil {
-		h.logger.Error("Failed to search movies for SSR", err)
-		http.ServeFile(w, r, filepath.Join(h.publicDir, "index.html"))
+		requestLogger(r, h.logger).Error("Failed to search movies for SSR", err)
+		h.serveIndex(w, r)
 		return
 	}
 
 	title := "Movies"
 	if query != "" {
 		title = "'" + query + "' movies"
+	} else if genre != nil {
+		for _, g := range genres {
+			if g.ID == *genre {
+				title = g.Name + " movies"
+			}
+		}
 	}
 
-	h.renderPage(w, "movies", PageData{
+	// Sorting does not change which movies match, so it stays out of the
+	// canonical URL
+	canonicalQuery := url.Values{}
+	if query != "" {
+		canonicalQuery.Set("q", query)
+	}
+	if genreStr != "" {
+		canonicalQuery.Set("genre", genreStr)
+	}
+	base := h.publicURL
+	canonical := base + "/movies"
+	if len(canonicalQuery) > 0 {
+		canonical += "?" + canonicalQuery.Encode()
+	}
+
+	h.renderPage(w, r, http.StatusOK, "movies", PageData{
 		Title:  title,
 		Movies: searchOutput.Movies,
-		Genres: genresOutput.Genres,
+		Genres: genres,
 		Query:  query,
 		Order:  order,
 		Genre:  genreStr,
+		Orders: searchOrders,
+	}, ssr.Meta{
+		Canonical:      canonical,
+		StructuredData: itemListStructuredData(searchOutput.Movies, title, canonical, base),
 	})
 }
 
-// renderPage renders an HTML page with SSR data
-func (h *SSRHandler) renderPage(w http.ResponseWriter, pageType string, data PageData) {
-	// Read the base HTML template
-	htmlPath := filepath.Join(h.publicDir, "index.html")
-	htmlBytes, err := os.ReadFile(htmlPath)
-	if err != nil {
-		h.logger.Error("Failed to read HTML template", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	html := string(htmlBytes)
-
-	// Inject SSR data as JSON in a script tag for hydration
-	ssrData := map[string]interface{}{
-		"pageType": pageType,
-		"data":     data,
-	}
+// notFoundPage answers a page URL naming nothing, such as a deleted movie,
+// with a 404 that search engines will not index.
+func (h *SSRHandler) notFoundPage(w http.ResponseWriter, r *http.Request) {
+	h.renderPage(w, r, http.StatusNotFound, "not-found", PageData{
+		Title:       "Page not found",
+		Description: "The page you are looking for does not exist or has been removed.",
+	}, ssr.Meta{})
+}
 
-	// Convert data to JSON
-	jsonBytes, err := json.Marshal(ssrData)
+// renderPage renders the page template named pageType with data and the
+// page's link preview and search engine metadata, falling back to the SPA
+// when rendering fails.
+func (h *SSRHandler) renderPage(w http.ResponseWriter, r *http.Request, status int, pageType string, data PageData, meta ssr.Meta) {
+	var buf bytes.Buffer
+	err := h.renderer.Render(&buf, pageType, ssr.Page{
+		Title:       data.Title,
+		Description: data.Description,
+		Meta:        meta,
+		Nonce:       CSPNonce(r.Context()),
+		Data:        data,
+	})
 	if err != nil {
-		h.logger.Error("Failed to marshal SSR data", err)
-	} else {
-		// For JSON in <script type="application/json">, we only need to escape </script>
-		// Do NOT use HTMLEscapeString as it corrupts the JSON
-		jsonData := strings.ReplaceAll(string(jsonBytes), "</script>", "<\\/script>")
-		// Inject before closing </body>
-		ssrScript := `<script id="ssr-data" type="application/json">` + jsonData + `</script>`
-		html = strings.Replace(html, "</body>", ssrScript+"</body>", 1)
-	}
-
-	// Update title and meta description
-	if data.Title != "" {
-		html = strings.Replace(html, "<title>Moovies</title>", "<title>"+template.HTMLEscapeString(data.Title)+"</title>", 1)
-	}
-
-	if data.Description != "" {
-		metaDesc := `<meta name="description" content="` + template.HTMLEscapeString(data.Description) + `">`
-		html = strings.Replace(html, "</head>", metaDesc+"</head>", 1)
+		requestLogger(r, h.logger).Error("Failed to render SSR page", err, "page", pageType)
+		h.serveIndex(w, r)
+		return
 	}
 
-	// Inject pre-rendered content into <main>
-	mainContent := h.renderMainContent(pageType, data)
-	html = strings.Replace(html, "<main></main>", "<main>"+mainContent+"</main>", 1)
-
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(html))
-}
-
-// renderMainContent renders the main content for each page type
-func (h *SSRHandler) renderMainContent(pageType string, data PageData) string {
-	switch pageType {
-	case "home":
-		return h.renderHomeContent(data)
-	case "movie-details":
-		return h.renderMovieDetailsContent(data)
-	case "movies":
-		return h.renderMoviesContent(data)
-	default:
-		return ""
-	}
-}
-
-// renderHomeContent renders the home page content
-func (h *SSRHandler) renderHomeContent(data PageData) string {
-	var html strings.Builder
-
-	html.WriteString(`<section class="vertical-scroll" id="top-10"><h2>Top 10 This Week</h2><ul>`)
-	for _, movie := range data.TopMovies {
-		html.WriteString(h.renderMovieItem(movie))
-	}
-	html.WriteString(`</ul></section>`)
-
-	html.WriteString(`<section class="vertical-scroll" id="random"><h2>Discover Something New</h2><ul>`)
-	for _, movie := range data.RandomMovies {
-		html.WriteString(h.renderMovieItem(movie))
-	}
-	html.WriteString(`</ul></section>`)
-
-	return html.String()
-}
-
-// renderMovieDetailsContent renders movie details page content
-func (h *SSRHandler) renderMovieDetailsContent(data PageData) string {
-	if data.Movie == nil {
-		return ""
-	}
-
-	m := data.Movie
-	var html strings.Builder
-	html.WriteString(`<article id="movie">`)
-
-	html.WriteString(`<h2>` + template.HTMLEscapeString(m.Title) + `</h2>`)
-	if m.Tagline != nil && *m.Tagline != "" {
-		html.WriteString(`<h3>` + template.HTMLEscapeString(*m.Tagline) + `</h3>`)
-	}
-
-	html.WriteString(`<header>`)
-
-	if m.PosterURL != nil && *m.PosterURL != "" {
-		html.WriteString(`<img src="` + template.HTMLEscapeString(*m.PosterURL) + `" alt="` + template.HTMLEscapeString(m.Title) + ` Poster" />`)
-	}
-
-	if m.TrailerURL != nil && *m.TrailerURL != "" {
-		html.WriteString(`<youtube-embed id="trailer" data-url="` + template.HTMLEscapeString(*m.TrailerURL) + `">YouTube loading...</youtube-embed>`)
-	}
-
-	html.WriteString(`<section id="actions" data-id="` + strconv.Itoa(m.ID) + `"><dl id="metadata">`)
-
-	html.WriteString(`<dt>Release Year</dt><dd>` + strconv.Itoa(m.ReleaseYear) + `</dd>`)
-	if m.Score != nil {
-		html.WriteString(`<dt>Score</dt><dd>` + fmt.Sprintf("%.1f", *m.Score) + ` / 10</dd>`)
-	}
-	if m.Popularity != nil {
-		html.WriteString(`<dt>Popularity</dt><dd>` + fmt.Sprintf("%.1f", *m.Popularity) + `</dd>`)
-	}
-
-	html.WriteString(`</dl><button id="btnFavorites">Add to Favorites</button><button id="btnWatchlist">Add to Watchlist</button></section></header>`)
-
-	if len(m.Genres) > 0 {
-		html.WriteString(`<ul id="genres">`)
-		for _, genre := range m.Genres {
-			html.WriteString(`<li>` + template.HTMLEscapeString(genre.Name) + `</li>`)
-		}
-		html.WriteString(`</ul>`)
-	}
-
-	if m.Overview != nil && *m.Overview != "" {
-		html.WriteString(`<p id="overview">` + template.HTMLEscapeString(*m.Overview) + `</p>`)
-	}
-
-	if len(m.Casting) > 0 {
-		html.WriteString(`<ul id="cast">`)
-		for _, actor := range m.Casting {
-			html.WriteString(`<li>`)
-			if actor.ImageURL != nil && *actor.ImageURL != "" {
-				html.WriteString(`<img src="` + template.HTMLEscapeString(*actor.ImageURL) + `" alt="` + template.HTMLEscapeString(actor.FirstName+" "+actor.LastName) + `" />`)
-			} else {
-				html.WriteString(`<img src="/images/generic_actor.jpg" alt="` + template.HTMLEscapeString(actor.FirstName+" "+actor.LastName) + `" />`)
-			}
-			html.WriteString(`<p>` + template.HTMLEscapeString(actor.FirstName+" "+actor.LastName) + `</p>`)
-			html.WriteString(`</li>`)
-		}
-		html.WriteString(`</ul>`)
-	}
-
-	html.WriteString(`</article>`)
-	return html.String()
-}
-
-// renderMoviesContent renders search results page content
-func (h *SSRHandler) renderMoviesContent(data PageData) string {
-	var html strings.Builder
-
-	html.WriteString(`<section><div id="search-header">`)
-	if data.Query != "" {
-		html.WriteString(`<h2>'` + template.HTMLEscapeString(data.Query) + `' movies</h2>`)
-	} else {
-		html.WriteString(`<h2>Movies</h2>`)
-	}
-
-	html.WriteString(`<section id="filters"><select id="filter" onchange="app.searchFilterChange(this.value)"><option>Filter by Genre</option>`)
-	for _, genre := range data.Genres {
-		selected := ""
-		if strconv.Itoa(genre.ID) == data.Genre {
-			selected = " selected"
-		}
-		html.WriteString(`<option value="` + strconv.Itoa(genre.ID) + `"` + selected + `>` + template.HTMLEscapeString(genre.Name) + `</option>`)
-	}
-	html.WriteString(`</select><select id="order" onchange="app.searchOrderChange(this.value)">`)
-	orders := []struct{ value, label string }{
-		{"popularity", "Sort by Popularity"},
-		{"score", "Sort by Score"},
-		{"date", "Sort by Release Date"},
-		{"name", "Sort by Name"},
-	}
-	for _, o := range orders {
-		selected := ""
-		if o.value == data.Order {
-			selected = " selected"
-		}
-		html.WriteString(`<option value="` + o.value + `"` + selected + `>` + o.label + `</option>`)
-	}
-	html.WriteString(`</select></section></div><ul id="movies-result">`)
-
-	for _, movie := range data.Movies {
-		html.WriteString(h.renderMovieItem(movie))
-	}
-
-	html.WriteString(`</ul></section>`)
-	return html.String()
-}
-
-// renderMovieItem renders a single movie item
-func (h *SSRHandler) renderMovieItem(movie models.Movie) string {
-	var html strings.Builder
-	html.WriteString(`<li><movie-item>`)
-
-	posterURL := ""
-	if movie.PosterURL != nil {
-		posterURL = *movie.PosterURL
-	}
-
-	html.WriteString(`<a href="/movies/` + strconv.Itoa(movie.ID) + `" class="navlink">`)
-	html.WriteString(`<article>`)
-	if posterURL != "" {
-		html.WriteString(`<img src="` + template.HTMLEscapeString(posterURL) + `" alt="` + template.HTMLEscapeString(movie.Title) + ` Poster" />`)
-	}
-	html.WriteString(`<p>` + template.HTMLEscapeString(movie.Title) + ` (` + strconv.Itoa(movie.ReleaseYear) + `)</p>`)
-	html.WriteString(`</article></a></movie-item></li>`)
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
 
-	return html.String()
+	h.metrics.SSRRendered(pageType, h.isCrawler(r))
 }
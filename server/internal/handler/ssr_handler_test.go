@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMovieDetailsPageNotFound(t *testing.T) {
+	ssr := newTestSSRHandler(t, testCatalogue(3), nil)
+
+	for _, path := range []string{"/movies/404", "/movies/abc"} {
+		t.Run(path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ssr.MovieDetailsPage(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", rec.Code)
+			}
+			body := rec.Body.String()
+			for _, want := range []string{`<section id="not-found">`, `<meta name="robots" content="noindex" />`} {
+				if !strings.Contains(body, want) {
+					t.Errorf("page is missing %s", want)
+				}
+			}
+			if strings.Contains(body, `rel="canonical"`) {
+				t.Error("not-found page has a canonical link")
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	ssr.MovieDetailsPage(rec, httptest.NewRequest(http.MethodGet, "/movies/2", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Movie 2") {
+		t.Errorf("existing movie: status = %d", rec.Code)
+	}
+}
+
+func TestPagesIgnoreRequestHost(t *testing.T) {
+	ssr := newTestSSRHandler(t, testCatalogue(3), nil)
+	pages := map[string]http.HandlerFunc{
+		"/":               ssr.HomePage,
+		"/movies/2":       ssr.MovieDetailsPage,
+		"/movies?q=movie": ssr.MoviesPage,
+	}
+	for path, page := range pages {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Host = "attacker.example"
+			req.Header.Set("X-Forwarded-Proto", "http")
+			rec := httptest.NewRecorder()
+			page(rec, req)
+
+			body := rec.Body.String()
+			if strings.Contains(body, "attacker.example") {
+				t.Error("page links to the request's Host")
+			}
+			if !strings.Contains(body, `<link rel="canonical" href="`+testPublicURL) {
+				t.Errorf("canonical link does not start with %s", testPublicURL)
+			}
+		})
+	}
+}
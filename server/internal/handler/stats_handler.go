@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	accountuc "github.com/jgamaraalv/movies.git/internal/usecase/account"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type StatsHandler struct {
+	getStatsUC *accountuc.GetStatsUseCase
+	logger     *logger.Logger
+}
+
+func NewStatsHandler(repo repository.StatsRepository, log *logger.Logger) *StatsHandler {
+	return &StatsHandler{
+		getStatsUC: accountuc.NewGetStatsUseCase(repo, log),
+		logger:     log,
+	}
+}
+
+func (h *StatsHandler) writeJSONResponse(w http.ResponseWriter, data interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("Failed to encode response", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return err
+	}
+	return nil
+}
+
+func (h *StatsHandler) handleError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err == nil {
+		return false
+	}
+	writeError(w, r, h.logger, err)
+	return true
+}
+
+// GetStats returns the user's collection statistics, or a yearly summary
+// when ?year= is given.
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	email, ok := r.Context().Value(emailContextKey).(string)
+	if !ok {
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Unable to retrieve email")
+		return
+	}
+
+	year := 0
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil {
+			writeInvalidField(w, r, "year", "Invalid year")
+			return
+		}
+		year = parsed
+	}
+
+	output, err := h.getStatsUC.Execute(r.Context(), accountuc.GetStatsInput{Email: email, Year: year})
+	if h.handleError(w, r, err) {
+		return
+	}
+
+	h.writeJSONResponse(w, output.Stats)
+}
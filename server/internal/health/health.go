@@ -0,0 +1,187 @@
+// Package health runs the liveness and readiness checks behind /livez and
+// /readyz. Subsystems register their own checks; each reports ok, degraded
+// (the service works with reduced functionality) or down.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// defaultTimeout bounds each check so one hanging dependency cannot stall
+// the probe.
+const defaultTimeout = 2 * time.Second
+
+// Check is a named probe. A failing Critical check takes the service down
+// (HTTP 503); a failing non-critical one, or any check returning an error
+// made by Degraded, only marks it degraded.
+type Check struct {
+	Name     string
+	Critical bool
+	// Timeout overrides the default per-check timeout.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type degradedError struct {
+	msg string
+}
+
+func (e *degradedError) Error() string { return e.msg }
+
+// Degraded builds the error a check returns when its subsystem is
+// deliberately off or running in a fallback mode, rather than failing.
+func Degraded(format string, args ...interface{}) error {
+	return &degradedError{msg: fmt.Sprintf(format, args...)}
+}
+
+type Result struct {
+	Status     Status  `json:"status"`
+	Critical   bool    `json:"critical"`
+	Message    string  `json:"message,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+type Report struct {
+	Status     Status            `json:"status"`
+	Checks     map[string]Result `json:"checks,omitempty"`
+	DurationMS float64           `json:"duration_ms"`
+}
+
+type Checker struct {
+	mu        sync.RWMutex
+	liveness  []Check
+	readiness []Check
+}
+
+func New() *Checker {
+	return &Checker{}
+}
+
+// AddLiveness registers a check for /livez. Liveness failures get the
+// process restarted, so only check what a restart would fix.
+func (c *Checker) AddLiveness(check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.liveness = append(c.liveness, check)
+}
+
+// AddReadiness registers a check for /readyz, which decides whether the
+// instance should receive traffic.
+func (c *Checker) AddReadiness(check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readiness = append(c.readiness, check)
+}
+
+// Live runs the liveness checks.
+func (c *Checker) Live(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]Check(nil), c.liveness...)
+	c.mu.RUnlock()
+	return run(ctx, checks)
+}
+
+// Ready runs the readiness checks.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]Check(nil), c.readiness...)
+	c.mu.RUnlock()
+	return run(ctx, checks)
+}
+
+// LiveHandler and ReadyHandler serve the reports as JSON: 200 when ok or
+// degraded, 503 when down.
+func (c *Checker) LiveHandler() http.Handler {
+	return reportHandler(c.Live)
+}
+
+func (c *Checker) ReadyHandler() http.Handler {
+	return reportHandler(c.Ready)
+}
+
+func reportHandler(report func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rep := report(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if rep.Status == StatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(rep)
+	})
+}
+
+// run executes checks concurrently; the overall status is the worst one.
+func run(ctx context.Context, checks []Check) Report {
+	start := time.Now()
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	for i, check := range checks {
+		report.Checks[check.Name] = results[i]
+		if worse(results[i].Status, report.Status) {
+			report.Status = results[i].Status
+		}
+	}
+	report.DurationMS = milliseconds(time.Since(start))
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := Result{Status: StatusOK, Critical: check.Critical, DurationMS: milliseconds(time.Since(start))}
+
+	var degraded *degradedError
+	switch {
+	case err == nil:
+	case errors.As(err, &degraded), !check.Critical:
+		result.Status = StatusDegraded
+		result.Message = err.Error()
+	default:
+		result.Status = StatusDown
+		result.Message = err.Error()
+	}
+	return result
+}
+
+var severity = map[Status]int{StatusOK: 0, StatusDegraded: 1, StatusDown: 2}
+
+func worse(a, b Status) bool {
+	return severity[a] > severity[b]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,24 @@
+// Package cache puts a read-through cache in front of repositories whose
+// data is the same for every visitor. Values are stored encoded, so a
+// Backend can be the in-process LRU or a cache shared between replicas.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores encoded values by key. Implementations must be safe for
+// concurrent use. Decorators log backend errors and fall back to the
+// wrapped repository, so an unavailable shared backend only costs latency.
+type Backend interface {
+	// Get returns the value stored under key and whether it was found and
+	// has not expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, if present.
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	m.Set(ctx, "a", []byte("1"), time.Minute)
+	m.Set(ctx, "b", []byte("2"), time.Minute)
+
+	// Reading a makes b the least recently used
+	if _, ok, _ := m.Get(ctx, "a"); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	m.Set(ctx, "c", []byte("3"), time.Minute)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := m.Get(ctx, key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+	if n := m.order.Len(); n != 2 {
+		t.Errorf("holds %d entries, want 2", n)
+	}
+}
+
+func TestMemoryUpdateDoesNotEvict(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	m.Set(ctx, "a", []byte("1"), time.Minute)
+	m.Set(ctx, "b", []byte("2"), time.Minute)
+	m.Set(ctx, "a", []byte("updated"), time.Minute)
+
+	if value, ok, _ := m.Get(ctx, "a"); !ok || string(value) != "updated" {
+		t.Errorf("Get(a) = %q, %v, want updated", value, ok)
+	}
+	if _, ok, _ := m.Get(ctx, "b"); !ok {
+		t.Error("b was evicted by an update")
+	}
+}
+
+func TestMemoryExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMemory(10)
+	m.now = func() time.Time { return now }
+
+	m.Set(ctx, "short", []byte("1"), time.Minute)
+	m.Set(ctx, "long", []byte("2"), time.Hour)
+
+	now = now.Add(time.Minute - time.Nanosecond)
+	if _, ok, _ := m.Get(ctx, "short"); !ok {
+		t.Error("short expired before its ttl")
+	}
+
+	now = now.Add(time.Nanosecond)
+	if _, ok, _ := m.Get(ctx, "short"); ok {
+		t.Error("short still served at its ttl")
+	}
+	if _, ok := m.entries["short"]; ok {
+		t.Error("expired entry was not dropped when read")
+	}
+	if _, ok, _ := m.Get(ctx, "long"); !ok {
+		t.Error("long expired with short")
+	}
+}
+
+func TestMemoryDelete(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	for _, key := range []string{"movies:top", "movies:id:1", "sitemap:pages.xml"} {
+		m.Set(ctx, key, []byte("x"), time.Minute)
+	}
+
+	m.Delete(ctx, "movies:id:1")
+	m.DeletePrefix(ctx, "movies:")
+
+	for key, want := range map[string]bool{"movies:top": false, "movies:id:1": false, "sitemap:pages.xml": true} {
+		if _, ok, _ := m.Get(ctx, key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+}
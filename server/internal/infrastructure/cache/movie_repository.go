@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// movieKeyPrefix namespaces the catalogue keys, so invalidation leaves
+// anything else in a shared backend alone.
+const movieKeyPrefix = "movies:"
+
+// MovieRepository decorates a repository.MovieRepository with a
+// read-through cache of the lookups every visitor shares: top movies, all
+// genres and movie details. Concurrent misses on one key are collapsed into
+// a single query. Every other method goes straight to the wrapped
+// repository.
+type MovieRepository struct {
+	repository.MovieRepository
+	backend Backend
+	ttl     time.Duration
+	flights singleflight.Group
+	metrics *metrics.Metrics
+	logger  *logger.Logger
+}
+
+func NewMovieRepository(next repository.MovieRepository, backend Backend, ttl time.Duration, m *metrics.Metrics, log *logger.Logger) *MovieRepository {
+	return &MovieRepository{
+		MovieRepository: next,
+		backend:         backend,
+		ttl:             ttl,
+		metrics:         m,
+		logger:          log,
+	}
+}
+
+func (r *MovieRepository) GetTopMovies(ctx context.Context) ([]models.Movie, error) {
+	return readThrough(ctx, r, "top_movies", movieKeyPrefix+"top", r.MovieRepository.GetTopMovies)
+}
+
+func (r *MovieRepository) GetAllGenres(ctx context.Context) ([]models.Genre, error) {
+	return readThrough(ctx, r, "genres", movieKeyPrefix+"genres", r.MovieRepository.GetAllGenres)
+}
+
+func (r *MovieRepository) GetMovieByID(ctx context.Context, id int) (models.Movie, error) {
+	return readThrough(ctx, r, "movie", movieKey(id), func(ctx context.Context) (models.Movie, error) {
+		return r.MovieRepository.GetMovieByID(ctx, id)
+	})
+}
+
+// InvalidateCatalogue drops every cached catalogue lookup. The API calls it
+// when a catalogue import commits.
+func (r *MovieRepository) InvalidateCatalogue(ctx context.Context) {
+	if err := r.backend.DeletePrefix(ctx, movieKeyPrefix); err != nil {
+		r.logger.Error("Failed to invalidate catalogue cache", err)
+	}
+}
+
+// InvalidateMovie drops the cached details of one movie.
+func (r *MovieRepository) InvalidateMovie(ctx context.Context, id int) {
+	if err := r.backend.Delete(ctx, movieKey(id)); err != nil {
+		r.logger.Error("Failed to invalidate cached movie", err, "movie_id", id)
+	}
+}
+
+func movieKey(id int) string {
+	return movieKeyPrefix + "id:" + strconv.Itoa(id)
+}
+
+// readThrough returns the value cached under key or loads, caches and
+// returns it. Each caller decodes its own copy, so callers may modify what
+// they get. Backend errors are logged and treated as misses; errors from
+// load (including not found) are never cached.
+func readThrough[T any](ctx context.Context, r *MovieRepository, name, key string, load func(context.Context) (T, error)) (T, error) {
+	var value T
+
+	encoded, ok, err := r.backend.Get(ctx, key)
+	if err != nil {
+		r.logger.Warn("Catalogue cache read failed", "key", key, "error", err.Error())
+	}
+	r.metrics.CacheLookup(name, ok)
+	if !ok {
+		flight := r.flights.DoChan(key, func() (any, error) {
+			// Shared by every caller waiting on key, so one of them going
+			// away must not cancel it; the statement timeout still bounds it
+			loadCtx := context.WithoutCancel(ctx)
+			loaded, err := load(loadCtx)
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := json.Marshal(loaded)
+			if err != nil {
+				return nil, err
+			}
+			if err := r.backend.Set(loadCtx, key, encoded, r.ttl); err != nil {
+				r.logger.Warn("Catalogue cache write failed", "key", key, "error", err.Error())
+			}
+			return encoded, nil
+		})
+		select {
+		case result := <-flight:
+			if result.Err != nil {
+				return value, result.Err
+			}
+			encoded = result.Val.([]byte)
+		case <-ctx.Done():
+			return value, ctx.Err()
+		}
+	}
+
+	err = json.Unmarshal(encoded, &value)
+	return value, err
+}
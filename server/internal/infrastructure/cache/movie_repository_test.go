@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// countingRepository counts the lookups that reach it. When release is
+// set, GetMovieByID blocks until it is closed.
+type countingRepository struct {
+	repository.MovieRepository
+	loads   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingRepository) GetMovieByID(ctx context.Context, id int) (models.Movie, error) {
+	r.loads.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	if id == 404 {
+		return models.Movie{}, repository.ErrMovieNotFound
+	}
+	return models.Movie{ID: id, Title: "Movie", Genres: []models.Genre{{ID: 1, Name: "Drama"}}}, nil
+}
+
+func (r *countingRepository) GetAllGenres(ctx context.Context) ([]models.Genre, error) {
+	r.loads.Add(1)
+	return []models.Genre{{ID: 1, Name: "Drama"}}, nil
+}
+
+// countingBackend counts Get calls, so a test can tell when every caller
+// has missed.
+type countingBackend struct {
+	Backend
+	gets atomic.Int32
+}
+
+func (b *countingBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b.gets.Add(1)
+	return b.Backend.Get(ctx, key)
+}
+
+func newTestRepository(t *testing.T, next repository.MovieRepository, backend Backend, m *metrics.Metrics) *MovieRepository {
+	t.Helper()
+	log, err := logger.New(logger.Options{Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewMovieRepository(next, backend, time.Minute, m, log)
+}
+
+func TestMovieRepositoryCollapsesConcurrentMisses(t *testing.T) {
+	const callers = 10
+	next := &countingRepository{release: make(chan struct{})}
+	backend := &countingBackend{Backend: NewMemory(10)}
+	repo := newTestRepository(t, next, backend, nil)
+
+	var wg sync.WaitGroup
+	movies := make([]models.Movie, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			movies[i], errs[i] = repo.GetMovieByID(context.Background(), 7)
+		}()
+	}
+
+	// Hold the load until every caller has missed and joined it
+	deadline := time.Now().Add(5 * time.Second)
+	for backend.gets.Load() < callers {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d callers reached the cache", backend.gets.Load(), callers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	if n := next.loads.Load(); n != 1 {
+		t.Errorf("%d concurrent misses made %d loads, want 1", callers, n)
+	}
+	for i := range callers {
+		if errs[i] != nil || movies[i].ID != 7 {
+			t.Fatalf("caller %d got %+v, %v", i, movies[i], errs[i])
+		}
+	}
+
+	// Each caller decodes its own copy
+	movies[0].Genres[0].Name = "changed"
+	if movies[1].Genres[0].Name != "Drama" {
+		t.Error("callers share the decoded value")
+	}
+}
+
+func TestMovieRepositoryServesFromCache(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepository{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	backend := NewMemory(10)
+	backend.now = func() time.Time { return now }
+	repo := newTestRepository(t, next, backend, nil)
+
+	for range 3 {
+		if _, err := repo.GetAllGenres(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := next.loads.Load(); n != 1 {
+		t.Errorf("3 lookups made %d loads, want 1", n)
+	}
+
+	now = now.Add(time.Minute)
+	repo.GetAllGenres(ctx)
+	if n := next.loads.Load(); n != 2 {
+		t.Errorf("lookup after the ttl made %d loads in total, want 2", n)
+	}
+
+	repo.InvalidateCatalogue(ctx)
+	repo.GetAllGenres(ctx)
+	if n := next.loads.Load(); n != 3 {
+		t.Errorf("lookup after invalidation made %d loads in total, want 3", n)
+	}
+}
+
+func TestMovieRepositoryDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRepository{}
+	repo := newTestRepository(t, next, NewMemory(10), nil)
+
+	for range 2 {
+		if _, err := repo.GetMovieByID(ctx, 404); err != repository.ErrMovieNotFound {
+			t.Fatalf("err = %v, want ErrMovieNotFound", err)
+		}
+	}
+	if n := next.loads.Load(); n != 2 {
+		t.Errorf("2 lookups of a missing movie made %d loads, want 2", n)
+	}
+}
+
+func TestMovieRepositoryCountsLookups(t *testing.T) {
+	ctx := context.Background()
+	m := metrics.New()
+	repo := newTestRepository(t, &countingRepository{}, NewMemory(10), m)
+
+	repo.GetMovieByID(ctx, 1)
+	repo.GetMovieByID(ctx, 1)
+	repo.GetMovieByID(ctx, 1)
+	repo.GetMovieByID(ctx, 2)
+	repo.GetAllGenres(ctx)
+
+	scrape := httptest.NewRecorder()
+	m.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(scrape.Body)
+	lines := strings.Split(string(body), "\n")
+
+	for _, want := range []string{
+		`movies_cache_lookups_total{cache="movie",result="hit"} 2`,
+		`movies_cache_lookups_total{cache="movie",result="miss"} 2`,
+		`movies_cache_lookups_total{cache="genres",result="miss"} 1`,
+	} {
+		found := false
+		for _, line := range lines {
+			found = found || line == want
+		}
+		if !found {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+const (
+	SourceLetterboxd = "letterboxd"
+	SourceIMDb       = "imdb"
+	SourceGeneric    = "csv"
+)
+
+const (
+	ListWatched   = "watched"
+	ListRatings   = "ratings"
+	ListWatchlist = "watchlist"
+)
+
+var (
+	ErrEmptyFile       = errors.New("import file is empty")
+	ErrMissingTitle    = errors.New("import file has no title column")
+	ErrUnsupportedList = errors.New("unsupported import list")
+)
+
+// Header aliases across Letterboxd, IMDb and hand-made exports.
+var (
+	titleColumns  = []string{"name", "title", "original title"}
+	yearColumns   = []string{"year"}
+	tmdbColumns   = []string{"tmdbid", "tmdb_id", "tmdb id"}
+	typeColumns   = []string{"title type"}
+	collColumns   = []string{"collection"}
+	ratingColumns = []string{"your rating", "rating"}
+	dateColumns   = []string{"date rated", "watcheddate", "watched date", "date", "time_added", "created"}
+)
+
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05"}
+
+func IsValidList(list string) bool {
+	switch list {
+	case ListWatched, ListRatings, ListWatchlist:
+		return true
+	default:
+		return false
+	}
+}
+
+// Parse reads a Letterboxd or IMDb CSV export. The list names which export
+// the file is (watched, ratings or watchlist) since Letterboxd uses the same
+// columns for several of them. Ratings are normalized to a 10-point scale.
+func Parse(r io.Reader, list string) ([]models.ImportRow, error) {
+	if !IsValidList(list) {
+		return nil, ErrUnsupportedList
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	titleIdx := findColumn(columns, titleColumns)
+	if titleIdx < 0 {
+		return nil, ErrMissingTitle
+	}
+	yearIdx := findColumn(columns, yearColumns)
+	tmdbIdx := findColumn(columns, tmdbColumns)
+	typeIdx := findColumn(columns, typeColumns)
+	collIdx := findColumn(columns, collColumns)
+	ratingIdx := findColumn(columns, ratingColumns)
+	dateIdx := findColumn(columns, dateColumns)
+
+	source := SourceGeneric
+	ratingScale := float32(1)
+	if _, ok := columns["letterboxd uri"]; ok {
+		source = SourceLetterboxd
+		ratingScale = 2 // Letterboxd rates 0.5-5 stars
+	} else if _, ok := columns["const"]; ok {
+		source = SourceIMDb
+	}
+
+	var rows []models.ImportRow
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, err
+		}
+
+		row := models.ImportRow{
+			Line:   line,
+			Source: source,
+			List:   list,
+			Title:  strings.TrimSpace(field(record, titleIdx)),
+		}
+		if row.Title == "" {
+			continue
+		}
+
+		if year, err := strconv.Atoi(strings.TrimSpace(field(record, yearIdx))); err == nil {
+			row.Year = year
+		}
+		if tmdbID, err := strconv.Atoi(strings.TrimSpace(field(record, tmdbIdx))); err == nil {
+			row.TMDBID = tmdbID
+		}
+		row.TitleType = strings.TrimSpace(field(record, typeIdx))
+		row.Collection = strings.ToLower(strings.TrimSpace(field(record, collIdx)))
+
+		if rating, err := strconv.ParseFloat(strings.TrimSpace(field(record, ratingIdx)), 32); err == nil {
+			normalized := float32(rating) * ratingScale
+			row.Rating = &normalized
+		}
+
+		if date, ok := parseDate(field(record, dateIdx)); ok {
+			row.Date = &date
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func findColumn(columns map[string]int, aliases []string) int {
+	for _, alias := range aliases {
+		if idx, ok := columns[alias]; ok {
+			return idx
+		}
+	}
+	return -1
+}
+
+func field(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return record[idx]
+}
+
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,156 @@
+package csvimport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+func rating(r float32) *float32 { return &r }
+
+func date(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		list  string
+		input string
+		want  []models.ImportRow
+	}{
+		{
+			name: "letterboxd watchlist",
+			list: ListWatchlist,
+			input: "Date,Name,Year,Letterboxd URI\n" +
+				"2024-01-05,Perfect Days,2023,https://boxd.it/uQnK\n" +
+				"2024-02-11,\"Crouching Tiger, Hidden Dragon\",2000,https://boxd.it/1ZyE\n",
+			want: []models.ImportRow{
+				{Line: 2, Source: SourceLetterboxd, List: ListWatchlist, Title: "Perfect Days", Year: 2023, Date: date("2024-01-05T00:00:00Z")},
+				{Line: 3, Source: SourceLetterboxd, List: ListWatchlist, Title: "Crouching Tiger, Hidden Dragon", Year: 2000, Date: date("2024-02-11T00:00:00Z")},
+			},
+		},
+		{
+			name: "letterboxd ratings are doubled to ten points",
+			list: ListRatings,
+			input: "Date,Name,Year,Letterboxd URI,Rating\n" +
+				"2023-11-02,Past Lives,2023,https://boxd.it/sBvW,4.5\n" +
+				"2023-11-03,Cats,2019,https://boxd.it/kZ6e,0.5\n",
+			want: []models.ImportRow{
+				{Line: 2, Source: SourceLetterboxd, List: ListRatings, Title: "Past Lives", Year: 2023, Rating: rating(9), Date: date("2023-11-02T00:00:00Z")},
+				{Line: 3, Source: SourceLetterboxd, List: ListRatings, Title: "Cats", Year: 2019, Rating: rating(1), Date: date("2023-11-03T00:00:00Z")},
+			},
+		},
+		{
+			name: "letterboxd diary prefers the watched date",
+			list: ListWatched,
+			input: "Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date\n" +
+				"2024-03-10,Dune: Part Two,2024,https://boxd.it/5Xt2Zk,4,,imax,2024-03-02\n" +
+				"2024-03-12,Heat,1995,https://boxd.it/5Yb9Qm,,Yes,,2024-03-11\n",
+			want: []models.ImportRow{
+				{Line: 2, Source: SourceLetterboxd, List: ListWatched, Title: "Dune: Part Two", Year: 2024, Rating: rating(8), Date: date("2024-03-02T00:00:00Z")},
+				{Line: 3, Source: SourceLetterboxd, List: ListWatched, Title: "Heat", Year: 1995, Date: date("2024-03-11T00:00:00Z")},
+			},
+		},
+		{
+			name: "imdb ratings with a byte order mark",
+			list: ListRatings,
+			input: "\ufeffConst,Your Rating,Date Rated,Title,Original Title,URL,Title Type,IMDb Rating,Runtime (mins),Year,Genres,Num Votes,Release Date,Directors\n" +
+				"tt0245429,9,2023-06-18,Spirited Away,Sen to Chihiro no kamikakushi,https://www.imdb.com/title/tt0245429,Movie,8.6,125,2001,\"Animation, Adventure, Family\",820000,2001-07-20,Hayao Miyazaki\n" +
+				"tt0903747,10,2023-06-19,Breaking Bad,Breaking Bad,https://www.imdb.com/title/tt0903747,TV Series,9.5,49,2008,\"Crime, Drama, Thriller\",2100000,2008-01-20,\n",
+			want: []models.ImportRow{
+				{Line: 2, Source: SourceIMDb, List: ListRatings, Title: "Spirited Away", Year: 2001, TitleType: "Movie", Rating: rating(9), Date: date("2023-06-18T00:00:00Z")},
+				{Line: 3, Source: SourceIMDb, List: ListRatings, Title: "Breaking Bad", Year: 2008, TitleType: "TV Series", Rating: rating(10), Date: date("2023-06-19T00:00:00Z")},
+			},
+		},
+		{
+			name: "own csv export",
+			list: ListWatched,
+			input: "collection,tmdb_id,title,year,time_added\n" +
+				"Favorites,129,Spirited Away,2001,2024-05-01T18:30:00Z\n" +
+				"watchlist,693134,Dune: Part Two,2024,\n",
+			want: []models.ImportRow{
+				{Line: 2, Source: SourceGeneric, List: ListWatched, Title: "Spirited Away", Year: 2001, TMDBID: 129, Collection: "favorites", Date: date("2024-05-01T18:30:00Z")},
+				{Line: 3, Source: SourceGeneric, List: ListWatched, Title: "Dune: Part Two", Year: 2024, TMDBID: 693134, Collection: "watchlist"},
+			},
+		},
+		{
+			name: "blank titles and short records are tolerated",
+			list: ListWatchlist,
+			input: "Date,Name,Year,Letterboxd URI\n" +
+				"2024-01-05,,2023,https://boxd.it/uQnK\n" +
+				"2024-01-06,Aftersun\n",
+			want: []models.ImportRow{
+				{Line: 3, Source: SourceLetterboxd, List: ListWatchlist, Title: "Aftersun", Date: date("2024-01-06T00:00:00Z")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input), tt.list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				if !sameRow(got[i], want) {
+					t.Errorf("row %d:\n got %s\nwant %s", i, formatRow(got[i]), formatRow(want))
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		input   string
+		wantErr error
+	}{
+		{"empty file", ListWatched, "", ErrEmptyFile},
+		{"no title column", ListWatched, "Date,Year,Letterboxd URI\n2024-01-05,2023,https://boxd.it/uQnK\n", ErrMissingTitle},
+		{"unknown list", "diary", "Date,Name,Year,Letterboxd URI\n", ErrUnsupportedList},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input), tt.list); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func sameRow(a, b models.ImportRow) bool {
+	if a.Line != b.Line || a.Source != b.Source || a.List != b.List || a.Title != b.Title ||
+		a.Year != b.Year || a.TMDBID != b.TMDBID || a.TitleType != b.TitleType || a.Collection != b.Collection {
+		return false
+	}
+	if (a.Rating == nil) != (b.Rating == nil) || a.Rating != nil && *a.Rating != *b.Rating {
+		return false
+	}
+	return (a.Date == nil) == (b.Date == nil) && (a.Date == nil || a.Date.Equal(*b.Date))
+}
+
+func formatRow(row models.ImportRow) string {
+	s := fmt.Sprintf("line %d %s/%s %q (%d) tmdb=%d type=%q collection=%q", row.Line, row.Source, row.List, row.Title, row.Year, row.TMDBID, row.TitleType, row.Collection)
+	if row.Rating != nil {
+		s += fmt.Sprintf(" rating=%g", *row.Rating)
+	}
+	if row.Date != nil {
+		s += " date=" + row.Date.Format(time.RFC3339)
+	}
+	return s
+}
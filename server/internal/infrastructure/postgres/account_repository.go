@@ -1,30 +1,36 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AccountRepository struct {
-	db     *sql.DB
+	db     *tracedDB
 	logger *logger.Logger
 }
 
 func NewAccountRepository(db *sql.DB, log *logger.Logger) (*AccountRepository, error) {
 	return &AccountRepository{
-		db:     db,
+		db:     newTracedDB(db),
 		logger: log,
 	}, nil
 }
 
-func (r *AccountRepository) Register(name, email, hashedPassword string) (bool, error) {
+func (r *AccountRepository) Register(ctx context.Context, name, email, hashedPassword string) (bool, error) {
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)
 	`, email).Scan(&exists)
 	if err != nil {
@@ -42,7 +48,7 @@ func (r *AccountRepository) Register(name, email, hashedPassword string) (bool,
 		RETURNING id
 	`
 	var userID int
-	err = r.db.QueryRow(
+	err = r.db.QueryRowContext(ctx,
 		query,
 		name,
 		email,
@@ -57,14 +63,14 @@ func (r *AccountRepository) Register(name, email, hashedPassword string) (bool,
 	return true, nil
 }
 
-func (r *AccountRepository) Authenticate(email string, password string) (bool, error) {
+func (r *AccountRepository) Authenticate(ctx context.Context, email string, password string) (bool, error) {
 	var user models.User
 	query := `
 		SELECT id, name, email, password_hashed
 		FROM users 
 		WHERE email = $1 AND time_deleted IS NULL
 	`
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
@@ -90,7 +96,7 @@ func (r *AccountRepository) Authenticate(email string, password string) (bool, e
 		SET last_login = $1
 		WHERE id = $2
 	`
-	_, err = r.db.Exec(updateQuery, time.Now(), user.ID)
+	_, err = r.db.ExecContext(ctx, updateQuery, time.Now(), user.ID)
 	if err != nil {
 		r.logger.Error("Failed to update last login", err)
 	}
@@ -98,20 +104,20 @@ func (r *AccountRepository) Authenticate(email string, password string) (bool, e
 	return true, nil
 }
 
-func (r *AccountRepository) GetAccountDetails(email string) (models.User, error) {
+func (r *AccountRepository) GetAccountDetails(ctx context.Context, email string) (models.User, error) {
 	var user models.User
 	query := `
 		SELECT id, name, email
 		FROM users 
 		WHERE email = $1 AND time_deleted IS NULL
 	`
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 	)
 	if err == sql.ErrNoRows {
-		r.logger.Error("User not found for email: "+email, nil)
+		r.logger.Error("User not found", nil, "email", email)
 		return models.User{}, repository.ErrUserNotFound
 	}
 	if err != nil {
@@ -127,7 +133,7 @@ func (r *AccountRepository) GetAccountDetails(email string) (models.User, error)
 		JOIN user_movies um ON m.id = um.movie_id
 		WHERE um.user_id = $1 AND um.relation_type = 'favorite'
 	`
-	favoriteRows, err := r.db.Query(favoritesQuery, user.ID)
+	favoriteRows, err := r.db.QueryContext(ctx, favoritesQuery, user.ID)
 	if err != nil {
 		r.logger.Error("Failed to query user favorites", err)
 		return user, err
@@ -146,6 +152,10 @@ func (r *AccountRepository) GetAccountDetails(email string) (models.User, error)
 		}
 		user.Favorites = append(user.Favorites, m)
 	}
+	if err := favoriteRows.Err(); err != nil {
+		r.logger.Error("Failed to read user favorites", err)
+		return user, err
+	}
 
 	watchlistQuery := `
 		SELECT m.id, m.tmdb_id, m.title, m.tagline, m.release_year, 
@@ -155,7 +165,7 @@ func (r *AccountRepository) GetAccountDetails(email string) (models.User, error)
 		JOIN user_movies um ON m.id = um.movie_id
 		WHERE um.user_id = $1 AND um.relation_type = 'watchlist'
 	`
-	watchlistRows, err := r.db.Query(watchlistQuery, user.ID)
+	watchlistRows, err := r.db.QueryContext(ctx, watchlistQuery, user.ID)
 	if err != nil {
 		r.logger.Error("Failed to query user watchlist", err)
 		return user, err
@@ -174,13 +184,17 @@ func (r *AccountRepository) GetAccountDetails(email string) (models.User, error)
 		}
 		user.Watchlist = append(user.Watchlist, m)
 	}
+	if err := watchlistRows.Err(); err != nil {
+		r.logger.Error("Failed to read user watchlist", err)
+		return user, err
+	}
 
 	return user, nil
 }
 
-func (r *AccountRepository) SaveCollection(user models.User, movieID int, collection string) (bool, error) {
+func (r *AccountRepository) SaveCollection(ctx context.Context, user models.User, movieID int, collection string) (bool, error) {
 	var userID int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT id 
 		FROM users 
 		WHERE email = $1 AND time_deleted IS NULL
@@ -195,7 +209,7 @@ func (r *AccountRepository) SaveCollection(user models.User, movieID int, collec
 	}
 
 	var exists bool
-	err = r.db.QueryRow(`
+	err = r.db.QueryRowContext(ctx, `
 		SELECT EXISTS(
 			SELECT 1 
 			FROM user_movies 
@@ -209,7 +223,7 @@ func (r *AccountRepository) SaveCollection(user models.User, movieID int, collec
 		return false, err
 	}
 	if exists {
-		r.logger.Info("Movie already in " + collection + " for user")
+		r.logger.Info("Movie already in collection", "collection", collection, "movie_id", movieID)
 		return true, nil
 	}
 
@@ -217,11 +231,140 @@ func (r *AccountRepository) SaveCollection(user models.User, movieID int, collec
 		INSERT INTO user_movies (user_id, movie_id, relation_type, time_added)
 		VALUES ($1, $2, $3, $4)
 	`
-	_, err = r.db.Exec(query, userID, movieID, collection, time.Now())
+	_, err = r.db.ExecContext(ctx, query, userID, movieID, collection, time.Now())
+	if isForeignKeyViolation(err) {
+		return false, repository.ErrMovieNotFound
+	}
 	if err != nil {
-		r.logger.Error("Failed to save movie to "+collection, err)
+		r.logger.Error("Failed to save movie to collection", err, "collection", collection)
 		return false, err
 	}
 
 	return true, nil
 }
+
+// SaveCollectionItems adds many movies to a user's collections in a single
+// transaction, keeping the original time_added of each item. Entries that are
+// already present are left untouched. It returns the number of rows inserted.
+func (r *AccountRepository) SaveCollectionItems(ctx context.Context, email string, items []models.CollectionItem) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id 
+		FROM users 
+		WHERE email = $1 AND time_deleted IS NULL
+	`, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		r.logger.Error("User not found", nil)
+		return 0, repository.ErrUserNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to query user ID", err)
+		return 0, err
+	}
+
+	// Statements inside the transaction are not traced individually; one
+	// span covers the whole batch.
+	ctx, span := tracing.Start(ctx, "AccountRepository.SaveCollectionItems.tx",
+		attribute.Int("import.items", len(items)))
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin collection import transaction", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO user_movies (user_id, movie_id, relation_type, time_added)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`)
+	if err != nil {
+		r.logger.Error("Failed to prepare collection import statement", err)
+		return 0, err
+	}
+	defer stmt.Close()
+
+	saved := 0
+	for _, item := range items {
+		timeAdded := item.TimeAdded
+		if timeAdded.IsZero() {
+			timeAdded = time.Now()
+		}
+		result, err := stmt.ExecContext(ctx, userID, item.MovieID, item.Collection, timeAdded)
+		if err != nil {
+			r.logger.Error("Failed to import movie to collection", err, "collection", item.Collection, "movie_id", item.MovieID)
+			return 0, err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			saved += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit collection import", err)
+		return 0, err
+	}
+	return saved, nil
+}
+
+// StreamCollection calls fn for every movie in the user's collections, or
+// only in collectionType when it is not empty, without loading them all into
+// memory. Iteration stops at the first error returned by fn.
+func (r *AccountRepository) StreamCollection(ctx context.Context, email string, collectionType string, fn func(models.CollectionEntry) error) error {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id 
+		FROM users 
+		WHERE email = $1 AND time_deleted IS NULL
+	`, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		r.logger.Error("User not found", nil)
+		return repository.ErrUserNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to query user ID", err)
+		return err
+	}
+
+	query := `
+		SELECT um.relation_type, um.time_added, m.id, m.tmdb_id, m.title, m.release_year
+		FROM user_movies um
+		JOIN movies m ON m.id = um.movie_id
+		WHERE um.user_id = $1 AND ($2 = '' OR um.relation_type = $2)
+		ORDER BY um.relation_type, um.time_added, m.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, collectionType)
+	if err != nil {
+		r.logger.Error("Failed to query user collection for export", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.CollectionEntry
+		var timeAdded sql.NullTime
+		if err := rows.Scan(
+			&entry.Collection, &timeAdded,
+			&entry.Movie.ID, &entry.Movie.TMDB_ID, &entry.Movie.Title, &entry.Movie.ReleaseYear,
+		); err != nil {
+			r.logger.Error("Failed to scan collection export row", err)
+			return err
+		}
+		if timeAdded.Valid {
+			entry.TimeAdded = timeAdded.Time
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// isForeignKeyViolation reports whether err is Postgres refusing a row that
+// references a missing one, such as a collection entry for an unknown movie.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("connection refused"), false},
+		{&pq.Error{Code: "23503"}, true},
+		{fmt.Errorf("save: %w", &pq.Error{Code: "23503"}), true},
+		// unique_violation
+		{&pq.Error{Code: "23505"}, false},
+	}
+	for _, tt := range tests {
+		if got := isForeignKeyViolation(tt.err); got != tt.want {
+			t.Errorf("isForeignKeyViolation(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
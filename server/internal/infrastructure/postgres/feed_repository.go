@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type FeedRepository struct {
+	db     *tracedDB
+	logger *logger.Logger
+}
+
+func NewFeedRepository(db *sql.DB, log *logger.Logger) (*FeedRepository, error) {
+	return &FeedRepository{
+		db:     newTracedDB(db),
+		logger: log,
+	}, nil
+}
+
+func (r *FeedRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM users WHERE email = $1 AND time_deleted IS NULL
+	`, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, repository.ErrUserNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get user ID by email", err)
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (r *FeedRepository) SaveFeedToken(ctx context.Context, userID int, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_feed_tokens (user_id, token_hash, time_created)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id)
+		DO UPDATE SET token_hash = EXCLUDED.token_hash, time_created = CURRENT_TIMESTAMP
+	`, userID, tokenHash)
+	if err != nil {
+		r.logger.Error("Failed to save feed token", err)
+		return err
+	}
+	return nil
+}
+
+func (r *FeedRepository) RevokeFeedToken(ctx context.Context, userID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_feed_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		r.logger.Error("Failed to revoke feed token", err)
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to read revoke result", err)
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *FeedRepository) GetUserIDByFeedToken(ctx context.Context, tokenHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT u.id
+		FROM user_feed_tokens ft
+		JOIN users u ON u.id = ft.user_id
+		WHERE ft.token_hash = $1 AND u.time_deleted IS NULL
+	`, tokenHash).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, repository.ErrFeedTokenNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to look up feed token", err)
+		return 0, err
+	}
+	return userID, nil
+}
+
+// GetCollectionVersion returns the latest time_added and the number of
+// entries in a collection, which together identify its current contents.
+func (r *FeedRepository) GetCollectionVersion(ctx context.Context, userID int, collectionType string) (time.Time, int, error) {
+	var lastModified sql.NullTime
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT MAX(time_added), COUNT(*)
+		FROM user_movies
+		WHERE user_id = $1 AND relation_type = $2
+	`, userID, collectionType).Scan(&lastModified, &count)
+	if err != nil {
+		r.logger.Error("Failed to get collection version", err)
+		return time.Time{}, 0, err
+	}
+	return lastModified.Time, count, nil
+}
+
+func (r *FeedRepository) GetCollectionEntries(ctx context.Context, userID int, collectionType string, limit int) ([]models.CollectionEntry, error) {
+	query := `
+		SELECT um.relation_type, um.time_added,
+		       m.id, m.tmdb_id, m.title, m.tagline, m.release_year, m.overview,
+		       m.score, m.popularity, m.language, m.poster_url, m.trailer_url
+		FROM user_movies um
+		JOIN movies m ON m.id = um.movie_id
+		WHERE um.user_id = $1 AND um.relation_type = $2
+		ORDER BY um.time_added DESC
+		LIMIT $3
+	`
+	return r.queryEntries(ctx, query, userID, collectionType, limit)
+}
+
+// GetRecommendationsVersion returns the latest computed_at and the number of
+// stored recommendations for a user.
+func (r *FeedRepository) GetRecommendationsVersion(ctx context.Context, userID int) (time.Time, int, error) {
+	var lastModified sql.NullTime
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT MAX(computed_at), COUNT(*)
+		FROM user_recommendations
+		WHERE user_id = $1
+	`, userID).Scan(&lastModified, &count)
+	if err != nil {
+		r.logger.Error("Failed to get recommendations version", err)
+		return time.Time{}, 0, err
+	}
+	return lastModified.Time, count, nil
+}
+
+func (r *FeedRepository) GetRecommendationEntries(ctx context.Context, userID int, limit int) ([]models.CollectionEntry, error) {
+	query := `
+		SELECT 'recommendation', ur.computed_at,
+		       m.id, m.tmdb_id, m.title, m.tagline, m.release_year, m.overview,
+		       m.score, m.popularity, m.language, m.poster_url, m.trailer_url
+		FROM user_recommendations ur
+		JOIN movies m ON m.id = ur.movie_id
+		WHERE ur.user_id = $1
+		ORDER BY ur.score DESC
+		LIMIT $2
+	`
+	return r.queryEntries(ctx, query, userID, limit)
+}
+
+func (r *FeedRepository) queryEntries(ctx context.Context, query string, args ...interface{}) ([]models.CollectionEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query feed entries", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.CollectionEntry, 0)
+	for rows.Next() {
+		var e models.CollectionEntry
+		var timeAdded sql.NullTime
+		m := &e.Movie
+		if err := rows.Scan(
+			&e.Collection, &timeAdded,
+			&m.ID, &m.TMDB_ID, &m.Title, &m.Tagline, &m.ReleaseYear, &m.Overview,
+			&m.Score, &m.Popularity, &m.Language, &m.PosterURL, &m.TrailerURL,
+		); err != nil {
+			r.logger.Error("Failed to scan feed entry row", err)
+			return nil, err
+		}
+		e.TimeAdded = timeAdded.Time
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval is how often an idle listener checks its
+	// connection, so a silently dropped one is noticed and re-established.
+	listenerPingInterval = 90 * time.Second
+)
+
+// ChangeListener calls onChange for every NOTIFY on a channel, on its own
+// connection outside the pool. Notifications sent while the connection is
+// down are lost, so onChange is also called after every reconnect.
+type ChangeListener struct {
+	listener *pq.Listener
+	channel  string
+	onChange func(ctx context.Context)
+	done     chan struct{}
+	stopped  chan struct{}
+	logger   *logger.Logger
+}
+
+// Listen starts listening on channel in the background. It does not wait
+// for the connection: until it is up, changes are simply not seen.
+func Listen(cfg config.DatabaseConfig, channel string, onChange func(ctx context.Context), log *logger.Logger) *ChangeListener {
+	l := &ChangeListener{
+		channel:  channel,
+		onChange: onChange,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		logger:   log,
+	}
+	l.listener = pq.NewListener(cfg.URL, listenerMinReconnect, listenerMaxReconnect, l.event)
+	go l.run()
+	return l
+}
+
+func (l *ChangeListener) event(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		l.logger.Warn("Lost the notification connection", "channel", l.channel, "error", errorString(err))
+	case pq.ListenerEventConnectionAttemptFailed:
+		l.logger.Warn("Failed to connect for notifications", "channel", l.channel, "error", errorString(err))
+	case pq.ListenerEventReconnected:
+		l.logger.Info("Reconnected for notifications", "channel", l.channel)
+	}
+}
+
+func (l *ChangeListener) run() {
+	defer close(l.stopped)
+
+	// Listen blocks until the first connection succeeds
+	if err := l.listener.Listen(l.channel); err != nil {
+		select {
+		case <-l.done:
+		default:
+			l.logger.Error("Failed to listen for notifications", err, "channel", l.channel)
+		}
+		return
+	}
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.listener.Notify:
+			// A nil notification marks a reconnect, after which anything
+			// may have changed
+			l.onChange(context.Background())
+		case <-ping.C:
+			if err := l.listener.Ping(); err != nil {
+				l.logger.Warn("Notification connection ping failed", "channel", l.channel, "error", err.Error())
+			}
+		}
+	}
+}
+
+// Close stops listening and closes the connection.
+func (l *ChangeListener) Close() error {
+	close(l.done)
+	err := l.listener.Close()
+	<-l.stopped
+	return err
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -1,31 +1,31 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
 
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type MovieRepository struct {
-	db     *sql.DB
+	db     *tracedDB
 	logger *logger.Logger
 }
 
 func NewMovieRepository(db *sql.DB, log *logger.Logger) (*MovieRepository, error) {
 	return &MovieRepository{
-		db:     db,
+		db:     newTracedDB(db),
 		logger: log,
 	}, nil
 }
 
 const defaultLimit = 20
 
-func (r *MovieRepository) GetTopMovies() ([]models.Movie, error) {
+func (r *MovieRepository) GetTopMovies(ctx context.Context) ([]models.Movie, error) {
 	query := `
 		SELECT id, tmdb_id, title, tagline, release_year, overview, score, 
 		       popularity, language, poster_url, trailer_url
@@ -33,10 +33,10 @@ func (r *MovieRepository) GetTopMovies() ([]models.Movie, error) {
 		ORDER BY popularity DESC
 		LIMIT $1
 	`
-	return r.getMovies(query)
+	return r.getMovies(ctx, query)
 }
 
-func (r *MovieRepository) GetRandomMovies() ([]models.Movie, error) {
+func (r *MovieRepository) GetRandomMovies(ctx context.Context) ([]models.Movie, error) {
 	randomQuery := `
 		SELECT id, tmdb_id, title, tagline, release_year, overview, score, 
 		       popularity, language, poster_url, trailer_url
@@ -44,11 +44,15 @@ func (r *MovieRepository) GetRandomMovies() ([]models.Movie, error) {
 		ORDER BY random()
 		LIMIT $1
 	`
-	return r.getMovies(randomQuery)
+	return r.getMovies(ctx, randomQuery)
 }
 
-func (r *MovieRepository) getMovies(query string) ([]models.Movie, error) {
-	rows, err := r.db.Query(query, defaultLimit)
+func (r *MovieRepository) getMovies(ctx context.Context, query string) ([]models.Movie, error) {
+	return r.queryMovies(ctx, query, defaultLimit)
+}
+
+func (r *MovieRepository) queryMovies(ctx context.Context, query string, args ...interface{}) ([]models.Movie, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to query movies", err)
 		return nil, err
@@ -69,17 +73,17 @@ func (r *MovieRepository) getMovies(query string) ([]models.Movie, error) {
 		movies = append(movies, m)
 	}
 
-	return movies, nil
+	return movies, rows.Err()
 }
 
-func (r *MovieRepository) GetMovieByID(id int) (models.Movie, error) {
+func (r *MovieRepository) GetMovieByID(ctx context.Context, id int) (models.Movie, error) {
 	query := `
 		SELECT id, tmdb_id, title, tagline, release_year, overview, score, 
 		       popularity, language, poster_url, trailer_url
 		FROM movies
 		WHERE id = $1
 	`
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id)
 
 	var m models.Movie
 	err := row.Scan(
@@ -96,14 +100,15 @@ func (r *MovieRepository) GetMovieByID(id int) (models.Movie, error) {
 		return models.Movie{}, err
 	}
 
-	if err := r.fetchMovieRelations(&m); err != nil {
+	movies := []models.Movie{m}
+	if err := r.LoadRelations(ctx, movies, repository.AllMovieRelations); err != nil {
 		return models.Movie{}, err
 	}
 
-	return m, nil
+	return movies[0], nil
 }
 
-func (r *MovieRepository) SearchMoviesByName(name string, order string, genre *int) ([]models.Movie, error) {
+func (r *MovieRepository) SearchMoviesByName(ctx context.Context, name string, order string, genre *int) ([]models.Movie, error) {
 	orderBy := "popularity DESC"
 	switch order {
 	case "score":
@@ -130,7 +135,7 @@ func (r *MovieRepository) SearchMoviesByName(name string, order string, genre *i
 		WHERE (title ILIKE $1 OR overview ILIKE $1) ` + genreFilter + `
 		ORDER BY ` + orderBy + `
 		LIMIT ` + limitParam
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Failed to search movies by name", err)
 		return nil, err
@@ -151,12 +156,62 @@ func (r *MovieRepository) SearchMoviesByName(name string, order string, genre *i
 		movies = append(movies, m)
 	}
 
-	return movies, nil
+	return movies, rows.Err()
+}
+
+func (r *MovieRepository) GetMoviesByTMDBIDs(ctx context.Context, tmdbIDs []int) ([]models.Movie, error) {
+	if len(tmdbIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT id, tmdb_id, title, tagline, release_year, overview, score,
+		       popularity, language, poster_url, trailer_url
+		FROM movies
+		WHERE tmdb_id = ANY($1)
+	`
+	return r.queryMovies(ctx, query, pq.Array(tmdbIDs))
+}
+
+func (r *MovieRepository) GetMoviesByReleaseYear(ctx context.Context, year int) ([]models.Movie, error) {
+	query := `
+		SELECT id, tmdb_id, title, tagline, release_year, overview, score,
+		       popularity, language, poster_url, trailer_url
+		FROM movies
+		WHERE release_year = $1
+	`
+	return r.queryMovies(ctx, query, year)
 }
 
-func (r *MovieRepository) GetAllGenres() ([]models.Genre, error) {
+func (r *MovieRepository) FindMoviesByTitle(ctx context.Context, title string) ([]models.Movie, error) {
+	query := `
+		SELECT id, tmdb_id, title, tagline, release_year, overview, score,
+		       popularity, language, poster_url, trailer_url
+		FROM movies
+		WHERE lower(title) = lower($1)
+		ORDER BY popularity DESC
+	`
+	return r.queryMovies(ctx, query, title)
+}
+
+func (r *MovieRepository) FindMoviesByTitleWords(ctx context.Context, words []string, limit int) ([]models.Movie, error) {
+	patterns := make([]string, len(words))
+	for i, word := range words {
+		patterns[i] = "%" + word + "%"
+	}
+	query := `
+		SELECT id, tmdb_id, title, tagline, release_year, overview, score,
+		       popularity, language, poster_url, trailer_url
+		FROM movies
+		WHERE title ILIKE ANY($1)
+		ORDER BY popularity DESC
+		LIMIT $2
+	`
+	return r.queryMovies(ctx, query, pq.Array(patterns), limit)
+}
+
+func (r *MovieRepository) GetAllGenres(ctx context.Context) ([]models.Genre, error) {
 	query := `SELECT id, name FROM genres ORDER BY id`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		r.logger.Error("Failed to query all genres", err)
 		return nil, err
@@ -172,72 +227,90 @@ func (r *MovieRepository) GetAllGenres() ([]models.Genre, error) {
 		}
 		genres = append(genres, g)
 	}
-	return genres, nil
+	return genres, rows.Err()
 }
 
-func (r *MovieRepository) fetchMovieRelations(m *models.Movie) error {
-	genreQuery := `
-		SELECT g.id, g.name 
+// relationQueries load one relation for a set of movies, keyed by movie_id.
+var relationQueries = map[repository.MovieRelation]string{
+	repository.RelationGenres: `
+		SELECT mg.movie_id, g.id, g.name
 		FROM genres g
 		JOIN movie_genres mg ON g.id = mg.genre_id
-		WHERE mg.movie_id = $1
-	`
-	genreRows, err := r.db.Query(genreQuery, m.ID)
-	if err != nil {
-		r.logger.Error("Failed to query genres for movie "+strconv.Itoa(m.ID), err)
-		return err
-	}
-	defer genreRows.Close()
-	for genreRows.Next() {
-		var g models.Genre
-		if err := genreRows.Scan(&g.ID, &g.Name); err != nil {
-			r.logger.Error("Failed to scan genre row", err)
-			return err
-		}
-		m.Genres = append(m.Genres, g)
-	}
-
-	actorQuery := `
-		SELECT a.id, a.first_name, a.last_name, a.image_url
+		WHERE mg.movie_id = ANY($1)
+	`,
+	repository.RelationCast: `
+		SELECT mc.movie_id, a.id, a.first_name, a.last_name, a.image_url
 		FROM actors a
 		JOIN movie_cast mc ON a.id = mc.actor_id
-		WHERE mc.movie_id = $1
-	`
-	actorRows, err := r.db.Query(actorQuery, m.ID)
-	if err != nil {
-		r.logger.Error("Failed to query actors for movie "+strconv.Itoa(m.ID), err)
-		return err
+		WHERE mc.movie_id = ANY($1)
+	`,
+	repository.RelationKeywords: `
+		SELECT mk.movie_id, k.word
+		FROM keywords k
+		JOIN movie_keywords mk ON k.id = mk.keyword_id
+		WHERE mk.movie_id = ANY($1)
+	`,
+}
+
+func (r *MovieRepository) LoadRelations(ctx context.Context, movies []models.Movie, relations []repository.MovieRelation) error {
+	if len(movies) == 0 {
+		return nil
 	}
-	defer actorRows.Close()
-	for actorRows.Next() {
-		var a models.Actor
-		if err := actorRows.Scan(&a.ID, &a.FirstName, &a.LastName, &a.ImageURL); err != nil {
-			r.logger.Error("Failed to scan actor row", err)
+	ids := make([]int, len(movies))
+	byID := make(map[int][]*models.Movie, len(movies))
+	for i := range movies {
+		ids[i] = movies[i].ID
+		byID[movies[i].ID] = append(byID[movies[i].ID], &movies[i])
+	}
+
+	for _, relation := range relations {
+		if err := r.loadRelation(ctx, relation, ids, byID); err != nil {
+			r.logger.Error("Failed to load movie relation", err, "relation", string(relation), "movies", len(ids))
 			return err
 		}
-		m.Casting = append(m.Casting, a)
 	}
+	return nil
+}
 
-	keywordQuery := `
-		SELECT k.word
-		FROM keywords k
-		JOIN movie_keywords mk ON k.id = mk.keyword_id
-		WHERE mk.movie_id = $1
-	`
-	keywordRows, err := r.db.Query(keywordQuery, m.ID)
+func (r *MovieRepository) loadRelation(ctx context.Context, relation repository.MovieRelation, ids []int, byID map[int][]*models.Movie) error {
+	query, ok := relationQueries[relation]
+	if !ok {
+		return repository.ErrInvalidMovieRelation
+	}
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
-		r.logger.Error("Failed to query keywords for movie "+strconv.Itoa(m.ID), err)
 		return err
 	}
-	defer keywordRows.Close()
-	for keywordRows.Next() {
-		var k string
-		if err := keywordRows.Scan(&k); err != nil {
-			r.logger.Error("Failed to scan keyword row", err)
-			return err
+	defer rows.Close()
+
+	for rows.Next() {
+		var movieID int
+		switch relation {
+		case repository.RelationGenres:
+			var g models.Genre
+			if err := rows.Scan(&movieID, &g.ID, &g.Name); err != nil {
+				return err
+			}
+			for _, m := range byID[movieID] {
+				m.Genres = append(m.Genres, g)
+			}
+		case repository.RelationCast:
+			var a models.Actor
+			if err := rows.Scan(&movieID, &a.ID, &a.FirstName, &a.LastName, &a.ImageURL); err != nil {
+				return err
+			}
+			for _, m := range byID[movieID] {
+				m.Casting = append(m.Casting, a)
+			}
+		case repository.RelationKeywords:
+			var k string
+			if err := rows.Scan(&movieID, &k); err != nil {
+				return err
+			}
+			for _, m := range byID[movieID] {
+				m.Keywords = append(m.Keywords, k)
+			}
 		}
-		m.Keywords = append(m.Keywords, k)
 	}
-
-	return nil
+	return rows.Err()
 }
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"database/sql"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+// Open returns a connection pool for cfg. The statement timeout is sent as
+// a session parameter, so Postgres itself aborts any statement that runs
+// longer; request cancellation is handled separately through each query's
+// context.
+func Open(cfg config.DatabaseConfig) (*sql.DB, error) {
+	dsn, err := withStatementTimeout(cfg.URL, cfg.StatementTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return sql.Open("postgres", dsn)
+}
+
+// withStatementTimeout adds statement_timeout to a URL or key=value DSN;
+// lib/pq forwards unknown settings to the server as run-time parameters.
+func withStatementTimeout(dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set("statement_timeout", ms)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	return dsn + " statement_timeout=" + ms, nil
+}
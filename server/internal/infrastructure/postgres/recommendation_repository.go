@@ -1,30 +1,65 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/lib/pq"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/metrics"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
 type RecommendationRepository struct {
-	db     *sql.DB
+	db     *tracedDB
 	logger *logger.Logger
+	// useFollowedUsers adds movies collected by followed users as candidates.
+	useFollowedUsers bool
+	metrics          *metrics.Metrics
 }
 
-func NewRecommendationRepository(db *sql.DB, log *logger.Logger) (*RecommendationRepository, error) {
+func NewRecommendationRepository(db *sql.DB, cfg config.RecommendationsConfig, m *metrics.Metrics, log *logger.Logger) (*RecommendationRepository, error) {
 	return &RecommendationRepository{
-		db:     db,
-		logger: log,
+		db:               newTracedDB(db),
+		logger:           log,
+		useFollowedUsers: cfg.FromFollows,
+		metrics:          m,
 	}, nil
 }
 
-func (r *RecommendationRepository) GetRecommendations(userID int, limit int) ([]models.Movie, error) {
+// CheckAvailability reports whether the pgvector extension and the tables
+// from the recommendations migration exist, so callers can turn the feature
+// off instead of failing every request.
+func (r *RecommendationRepository) CheckAvailability(ctx context.Context) error {
+	var hasVector bool
+	var missing []string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector'),
+			ARRAY(SELECT t FROM unnest(ARRAY['movie_embeddings', 'user_embeddings', 'user_recommendations']) AS t
+				WHERE to_regclass(t) IS NULL)
+	`).Scan(&hasVector, pq.Array(&missing))
+	if err != nil {
+		return err
+	}
+	if !hasVector {
+		return errors.New("pgvector extension is not installed")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (r *RecommendationRepository) GetRecommendations(ctx context.Context, userID int, limit int) ([]models.Movie, error) {
 	query := `
 		SELECT m.id, m.tmdb_id, m.title, m.tagline, m.release_year,
 		       m.overview, m.score, m.popularity, m.language,
@@ -35,7 +70,7 @@ func (r *RecommendationRepository) GetRecommendations(userID int, limit int) ([]
 		ORDER BY ur.score DESC
 		LIMIT $2
 	`
-	rows, err := r.db.Query(query, userID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
 	if err != nil {
 		r.logger.Error("Failed to query recommendations", err)
 		return nil, err
@@ -56,12 +91,12 @@ func (r *RecommendationRepository) GetRecommendations(userID int, limit int) ([]
 		movies = append(movies, m)
 	}
 
-	return movies, nil
+	return movies, rows.Err()
 }
 
-func (r *RecommendationRepository) HasRecommendations(userID int) (bool, error) {
+func (r *RecommendationRepository) HasRecommendations(ctx context.Context, userID int) (bool, error) {
 	var exists bool
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT EXISTS(SELECT 1 FROM user_recommendations WHERE user_id = $1)
 	`, userID).Scan(&exists)
 	if err != nil {
@@ -71,9 +106,9 @@ func (r *RecommendationRepository) HasRecommendations(userID int) (bool, error)
 	return exists, nil
 }
 
-func (r *RecommendationRepository) GetUserIDByEmail(email string) (int, error) {
+func (r *RecommendationRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
 	var userID int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRowContext(ctx, `
 		SELECT id FROM users WHERE email = $1 AND time_deleted IS NULL
 	`, email).Scan(&userID)
 	if err == sql.ErrNoRows {
@@ -86,8 +121,8 @@ func (r *RecommendationRepository) GetUserIDByEmail(email string) (int, error) {
 	return userID, nil
 }
 
-func (r *RecommendationRepository) InvalidateRecommendations(userID int) error {
-	_, err := r.db.Exec(`DELETE FROM user_recommendations WHERE user_id = $1`, userID)
+func (r *RecommendationRepository) InvalidateRecommendations(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_recommendations WHERE user_id = $1`, userID)
 	if err != nil {
 		r.logger.Error("Failed to invalidate recommendations", err)
 		return err
@@ -95,7 +130,7 @@ func (r *RecommendationRepository) InvalidateRecommendations(userID int) error {
 	return nil
 }
 
-func (r *RecommendationRepository) RecomputeUserEmbedding(userID int) error {
+func (r *RecommendationRepository) RecomputeUserEmbedding(ctx context.Context, userID int) error {
 	// Average of movie embeddings for user's collections using pgvector's native AVG
 	query := `
 		INSERT INTO user_embeddings (user_id, embedding, updated_at)
@@ -112,7 +147,7 @@ func (r *RecommendationRepository) RecomputeUserEmbedding(userID int) error {
 			embedding = EXCLUDED.embedding,
 			updated_at = CURRENT_TIMESTAMP
 	`
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID)
 	if err != nil {
 		r.logger.Error("Failed to recompute user embedding", err)
 		return err
@@ -131,18 +166,27 @@ type movieCandidate struct {
 	finalScore    float64
 }
 
-func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
+// ComputeRecommendations rebuilds the stored recommendations for a user and
+// reports its duration and candidate count to the metrics.
+func (r *RecommendationRepository) ComputeRecommendations(ctx context.Context, userID int) error {
+	start := time.Now()
+	candidates, err := r.computeRecommendations(ctx, userID)
+	r.metrics.RecommendationsComputed(time.Since(start), candidates, err)
+	return err
+}
+
+func (r *RecommendationRepository) computeRecommendations(ctx context.Context, userID int) (int, error) {
 	// 1. Delete existing recommendations
-	if _, err := r.db.Exec(`DELETE FROM user_recommendations WHERE user_id = $1`, userID); err != nil {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_recommendations WHERE user_id = $1`, userID); err != nil {
 		r.logger.Error("Failed to clear old recommendations", err)
-		return err
+		return 0, err
 	}
 
 	// 2. Get user's movies to exclude
-	rows, err := r.db.Query(`SELECT movie_id FROM user_movies WHERE user_id = $1`, userID)
+	rows, err := r.db.QueryContext(ctx, `SELECT movie_id FROM user_movies WHERE user_id = $1`, userID)
 	if err != nil {
 		r.logger.Error("Failed to get user movies", err)
-		return err
+		return 0, err
 	}
 	userMovieIDs := make(map[int]bool)
 	for rows.Next() {
@@ -151,13 +195,17 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 		userMovieIDs[mid] = true
 	}
 	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to read user movies", err)
+		return 0, err
+	}
 
 	if len(userMovieIDs) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	// 3. Get user's genre preferences (weighted by frequency)
-	genreRows, err := r.db.Query(`
+	genreRows, err := r.db.QueryContext(ctx, `
 		SELECT mg.genre_id, COUNT(*) as cnt
 		FROM user_movies um
 		JOIN movie_genres mg ON mg.movie_id = um.movie_id
@@ -167,7 +215,7 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 	`, userID)
 	if err != nil {
 		r.logger.Error("Failed to get user genre preferences", err)
-		return err
+		return 0, err
 	}
 	genreWeights := make(map[int]float64)
 	var totalGenreCount float64
@@ -178,6 +226,10 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 		totalGenreCount += float64(cnt)
 	}
 	genreRows.Close()
+	if err := genreRows.Err(); err != nil {
+		r.logger.Error("Failed to read user genre preferences", err)
+		return 0, err
+	}
 
 	// Normalize genre weights to [0, 1]
 	if totalGenreCount > 0 {
@@ -190,14 +242,14 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 
 	// 4. Embedding-based candidates (content-based via pgvector cosine distance)
 	var hasEmbedding bool
-	err = r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM user_embeddings WHERE user_id = $1)`, userID).Scan(&hasEmbedding)
+	err = r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM user_embeddings WHERE user_id = $1)`, userID).Scan(&hasEmbedding)
 	if err != nil {
 		r.logger.Error("Failed to check user embedding", err)
-		return err
+		return 0, err
 	}
 
 	if hasEmbedding {
-		embRows, err := r.db.Query(`
+		embRows, err := r.db.QueryContext(ctx, `
 			SELECT me.movie_id, 1 - (me.embedding <=> ue.embedding) as similarity
 			FROM movie_embeddings me
 			CROSS JOIN user_embeddings ue
@@ -216,10 +268,13 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 				candidates[mid] = &movieCandidate{movieID: mid, embSimilarity: sim}
 			}
 			embRows.Close()
+			if err := embRows.Err(); err != nil {
+				r.logger.Error("Failed to read embedding candidates", err)
+			}
 		}
 
 		// 5. Collaborative filtering: similar users' movies
-		collabRows, err := r.db.Query(`
+		collabRows, err := r.db.QueryContext(ctx, `
 			SELECT um.movie_id, um.relation_type
 			FROM user_embeddings ue_other
 			JOIN user_movies um ON um.user_id = ue_other.user_id
@@ -248,6 +303,46 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 				}
 			}
 			collabRows.Close()
+			if err := collabRows.Err(); err != nil {
+				r.logger.Error("Failed to read collaborative candidates", err)
+			}
+		}
+	}
+
+	// 5b. Social candidates: movies collected by users this user follows.
+	// Explicit follows are a stronger signal than embedding neighbours, so
+	// they weigh more in the collaborative score.
+	if r.useFollowedUsers {
+		followRows, err := r.db.QueryContext(ctx, `
+			SELECT um.movie_id, um.relation_type
+			FROM user_follows uf
+			JOIN user_movies um ON um.user_id = uf.followee_id
+			WHERE uf.follower_id = $1
+			AND um.movie_id NOT IN (SELECT movie_id FROM user_movies WHERE user_id = $1)
+			ORDER BY um.time_added DESC
+			LIMIT 200
+		`, userID)
+		if err != nil {
+			r.logger.Error("Failed to get followed users candidates", err)
+		} else {
+			for followRows.Next() {
+				var mid int
+				var relType string
+				followRows.Scan(&mid, &relType)
+				weight := 1.5
+				if relType == "watchlist" {
+					weight = 0.75
+				}
+				if c, exists := candidates[mid]; exists {
+					c.collabScore += weight
+				} else {
+					candidates[mid] = &movieCandidate{movieID: mid, collabScore: weight}
+				}
+			}
+			followRows.Close()
+			if err := followRows.Err(); err != nil {
+				r.logger.Error("Failed to read followed users candidates", err)
+			}
 		}
 	}
 
@@ -271,7 +366,7 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 			LIMIT 200
 		`, strings.Join(genreIDs, ","))
 
-		genreCandRows, err := r.db.Query(genreQuery, userID)
+		genreCandRows, err := r.db.QueryContext(ctx, genreQuery, userID)
 		if err != nil {
 			r.logger.Error("Failed to get genre candidates", err)
 		} else {
@@ -303,6 +398,9 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 				}
 			}
 			genreCandRows.Close()
+			if err := genreCandRows.Err(); err != nil {
+				r.logger.Error("Failed to read genre candidates", err)
+			}
 		}
 	}
 
@@ -320,7 +418,7 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 			placeholders[i] = fmt.Sprintf("$%d", i+1)
 			args[i] = mid
 		}
-		metaRows, err := r.db.Query(fmt.Sprintf(`
+		metaRows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
 			SELECT m.id, m.score, m.popularity, COALESCE(
 				(SELECT array_agg(mg.genre_id) FROM movie_genres mg WHERE mg.movie_id = m.id),
 				'{}'
@@ -345,6 +443,9 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 				}
 			}
 			metaRows.Close()
+			if err := metaRows.Err(); err != nil {
+				r.logger.Error("Failed to read candidate metadata", err)
+			}
 		}
 	}
 
@@ -406,7 +507,7 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 
 	// 11. Store recommendations
 	for _, c := range candidateList[:limit] {
-		_, err := r.db.Exec(`
+		_, err := r.db.ExecContext(ctx, `
 			INSERT INTO user_recommendations (user_id, movie_id, score, computed_at)
 			VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
 			ON CONFLICT (user_id, movie_id)
@@ -417,8 +518,8 @@ func (r *RecommendationRepository) ComputeRecommendations(userID int) error {
 		}
 	}
 
-	r.logger.Info(fmt.Sprintf("Computed %d recommendations for user %d (from %d candidates)", limit, userID, len(candidates)))
-	return nil
+	r.logger.Info("Computed recommendations", "user_id", userID, "count", limit, "candidates", len(candidates))
+	return len(candidates), nil
 }
 
 // parseIntArray parses a PostgreSQL int array string like "{1,2,3}" into a slice of ints
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type SitemapRepository struct {
+	db     *tracedDB
+	logger *logger.Logger
+}
+
+func NewSitemapRepository(db *sql.DB, log *logger.Logger) (*SitemapRepository, error) {
+	return &SitemapRepository{
+		db:     newTracedDB(db),
+		logger: log,
+	}, nil
+}
+
+func (r *SitemapRepository) GetMoviePages(ctx context.Context, size int) ([]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT max(time_updated)
+		FROM (
+			SELECT time_updated, (row_number() OVER (ORDER BY id) - 1) / $1 AS page
+			FROM movies
+		) paged
+		GROUP BY page
+		ORDER BY page
+	`, size)
+	if err != nil {
+		r.logger.Error("Failed to query movie sitemap pages", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []time.Time
+	for rows.Next() {
+		var lastModified time.Time
+		if err := rows.Scan(&lastModified); err != nil {
+			r.logger.Error("Failed to scan movie sitemap page", err)
+			return nil, err
+		}
+		pages = append(pages, lastModified)
+	}
+	return pages, rows.Err()
+}
+
+// StreamMoviePage skips earlier pages with OFFSET, which walks their rows
+// in the primary key index.
+func (r *SitemapRepository) StreamMoviePage(ctx context.Context, page int, size int, fn func(models.SitemapEntry) error) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, time_updated
+		FROM movies
+		ORDER BY id
+		LIMIT $1 OFFSET $2
+	`, size, page*size)
+	if err != nil {
+		r.logger.Error("Failed to query movie sitemap", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.SitemapEntry
+		if err := rows.Scan(&entry.ID, &entry.LastModified); err != nil {
+			r.logger.Error("Failed to scan movie sitemap row", err)
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SitemapRepository) GetGenres(ctx context.Context) ([]models.SitemapEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT g.id, max(m.time_updated)
+		FROM genres g
+		LEFT JOIN movie_genres mg ON mg.genre_id = g.id
+		LEFT JOIN movies m ON m.id = mg.movie_id
+		GROUP BY g.id
+		ORDER BY g.id
+	`)
+	if err != nil {
+		r.logger.Error("Failed to query genre sitemap", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var genres []models.SitemapEntry
+	for rows.Next() {
+		var entry models.SitemapEntry
+		var lastModified sql.NullTime
+		if err := rows.Scan(&entry.ID, &lastModified); err != nil {
+			r.logger.Error("Failed to scan genre sitemap row", err)
+			return nil, err
+		}
+		if lastModified.Valid {
+			entry.LastModified = lastModified.Time
+		}
+		genres = append(genres, entry)
+	}
+	return genres, rows.Err()
+}
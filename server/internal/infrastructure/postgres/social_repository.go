@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type SocialRepository struct {
+	db     *tracedDB
+	logger *logger.Logger
+}
+
+func NewSocialRepository(db *sql.DB, log *logger.Logger) (*SocialRepository, error) {
+	return &SocialRepository{
+		db:     newTracedDB(db),
+		logger: log,
+	}, nil
+}
+
+func (r *SocialRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM users WHERE email = $1 AND time_deleted IS NULL
+	`, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, repository.ErrUserNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get user ID by email", err)
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (r *SocialRepository) userExists(ctx context.Context, userID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND time_deleted IS NULL)
+	`, userID).Scan(&exists)
+	if err != nil {
+		r.logger.Error("Failed to check user existence", err)
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *SocialRepository) Follow(ctx context.Context, followerID int, followeeID int) (bool, error) {
+	exists, err := r.userExists(ctx, followeeID)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, repository.ErrUserNotFound
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_follows (follower_id, followee_id, time_created)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (follower_id, followee_id) DO NOTHING
+	`, followerID, followeeID)
+	if err != nil {
+		r.logger.Error("Failed to follow user", err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to read follow result", err)
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *SocialRepository) Unfollow(ctx context.Context, followerID int, followeeID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2
+	`, followerID, followeeID)
+	if err != nil {
+		r.logger.Error("Failed to unfollow user", err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to read unfollow result", err)
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *SocialRepository) GetFollowers(ctx context.Context, userID int, limit int, offset int) ([]models.UserSummary, error) {
+	query := `
+		SELECT u.id, u.name
+		FROM user_follows uf
+		JOIN users u ON u.id = uf.follower_id
+		WHERE uf.followee_id = $1 AND u.time_deleted IS NULL
+		ORDER BY uf.time_created DESC
+		LIMIT $2 OFFSET $3
+	`
+	return r.getUserSummaries(ctx, userID, query, limit, offset)
+}
+
+func (r *SocialRepository) GetFollowing(ctx context.Context, userID int, limit int, offset int) ([]models.UserSummary, error) {
+	query := `
+		SELECT u.id, u.name
+		FROM user_follows uf
+		JOIN users u ON u.id = uf.followee_id
+		WHERE uf.follower_id = $1 AND u.time_deleted IS NULL
+		ORDER BY uf.time_created DESC
+		LIMIT $2 OFFSET $3
+	`
+	return r.getUserSummaries(ctx, userID, query, limit, offset)
+}
+
+func (r *SocialRepository) getUserSummaries(ctx context.Context, userID int, query string, limit int, offset int) ([]models.UserSummary, error) {
+	exists, err := r.userExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, repository.ErrUserNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		r.logger.Error("Failed to query follow list", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.UserSummary, 0)
+	for rows.Next() {
+		var u models.UserSummary
+		if err := rows.Scan(&u.ID, &u.Name); err != nil {
+			r.logger.Error("Failed to scan follow list row", err)
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *SocialRepository) RecordActivity(ctx context.Context, event models.ActivityEvent) error {
+	payload := []byte(event.Payload)
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO activity_events (user_id, event_type, movie_id, payload, time_created)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+	`, event.UserID, event.EventType, event.MovieID, payload)
+	if err != nil {
+		r.logger.Error("Failed to record activity event", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SocialRepository) GetFeed(ctx context.Context, userID int, beforeID int64, limit int) ([]models.ActivityEvent, error) {
+	query := `
+		SELECT ae.id, ae.user_id, u.name, ae.event_type, ae.payload, ae.time_created,
+		       m.id, m.tmdb_id, m.title, m.release_year, m.poster_url
+		FROM activity_events ae
+		JOIN user_follows uf ON uf.followee_id = ae.user_id
+		JOIN users u ON u.id = ae.user_id
+		LEFT JOIN movies m ON m.id = ae.movie_id
+		WHERE uf.follower_id = $1
+		AND u.time_deleted IS NULL
+		AND ($2::bigint = 0 OR ae.id < $2::bigint)
+		ORDER BY ae.id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, beforeID, limit)
+	if err != nil {
+		r.logger.Error("Failed to query activity feed", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]models.ActivityEvent, 0)
+	for rows.Next() {
+		var e models.ActivityEvent
+		var payload []byte
+		var movieID, tmdbID, releaseYear sql.NullInt64
+		var title sql.NullString
+		var posterURL *string
+		if err := rows.Scan(
+			&e.ID, &e.UserID, &e.UserName, &e.EventType, &payload, &e.TimeCreated,
+			&movieID, &tmdbID, &title, &releaseYear, &posterURL,
+		); err != nil {
+			r.logger.Error("Failed to scan activity event row", err)
+			return nil, err
+		}
+		if len(payload) > 0 && string(payload) != "{}" {
+			e.Payload = payload
+		}
+		if movieID.Valid {
+			id := int(movieID.Int64)
+			e.MovieID = &id
+			e.Movie = &models.Movie{
+				ID:          id,
+				TMDB_ID:     int(tmdbID.Int64),
+				Title:       title.String,
+				ReleaseYear: int(releaseYear.Int64),
+				PosterURL:   posterURL,
+			}
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
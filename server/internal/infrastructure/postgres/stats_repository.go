@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type StatsRepository struct {
+	db     *tracedDB
+	logger *logger.Logger
+}
+
+func NewStatsRepository(db *sql.DB, log *logger.Logger) (*StatsRepository, error) {
+	return &StatsRepository{
+		db:     newTracedDB(db),
+		logger: log,
+	}, nil
+}
+
+// collectedMovies selects the distinct movies a user collected, restricted to
+// those added during $2 unless it is 0. A movie in both favorites and
+// watchlist is counted once.
+const collectedMovies = `
+	WITH collected AS (
+		SELECT DISTINCT um.movie_id
+		FROM user_movies um
+		WHERE um.user_id = $1
+		AND ($2 = 0 OR EXTRACT(YEAR FROM um.time_added) = $2)
+	)
+`
+
+func (r *StatsRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM users WHERE email = $1 AND time_deleted IS NULL
+	`, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, repository.ErrUserNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get user ID by email", err)
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (r *StatsRepository) GetCollectionTotals(ctx context.Context, userID int, year int) (models.CollectionTotals, error) {
+	var totals models.CollectionTotals
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE relation_type = 'favorite'),
+			COUNT(*) FILTER (WHERE relation_type = 'watchlist'),
+			COUNT(DISTINCT movie_id)
+		FROM user_movies
+		WHERE user_id = $1
+		AND ($2 = 0 OR EXTRACT(YEAR FROM time_added) = $2)
+	`, userID, year).Scan(&totals.Favorites, &totals.Watchlist, &totals.Movies)
+	if err != nil {
+		r.logger.Error("Failed to get collection totals", err)
+		return models.CollectionTotals{}, err
+	}
+	return totals, nil
+}
+
+func (r *StatsRepository) GetGenreDistribution(ctx context.Context, userID int, year int) ([]models.GenreStat, error) {
+	rows, err := r.db.QueryContext(ctx, collectedMovies+`
+		SELECT g.id, g.name, COUNT(*) AS cnt
+		FROM collected c
+		JOIN movie_genres mg ON mg.movie_id = c.movie_id
+		JOIN genres g ON g.id = mg.genre_id
+		GROUP BY g.id, g.name
+		ORDER BY cnt DESC, g.name
+	`, userID, year)
+	if err != nil {
+		r.logger.Error("Failed to get genre distribution", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]models.GenreStat, 0)
+	total := 0
+	for rows.Next() {
+		var s models.GenreStat
+		if err := rows.Scan(&s.GenreID, &s.Name, &s.Count); err != nil {
+			r.logger.Error("Failed to scan genre stat row", err)
+			return nil, err
+		}
+		total += s.Count
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if total > 0 {
+		for i := range stats {
+			stats[i].Share = float64(stats[i].Count) / float64(total)
+		}
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) GetDecadeDistribution(ctx context.Context, userID int, year int) ([]models.DecadeStat, error) {
+	rows, err := r.db.QueryContext(ctx, collectedMovies+`
+		SELECT (m.release_year / 10) * 10 AS decade, COUNT(*) AS cnt
+		FROM collected c
+		JOIN movies m ON m.id = c.movie_id
+		WHERE m.release_year > 0
+		GROUP BY decade
+		ORDER BY cnt DESC, decade DESC
+	`, userID, year)
+	if err != nil {
+		r.logger.Error("Failed to get decade distribution", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]models.DecadeStat, 0)
+	for rows.Next() {
+		var s models.DecadeStat
+		if err := rows.Scan(&s.Decade, &s.Count); err != nil {
+			r.logger.Error("Failed to scan decade stat row", err)
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *StatsRepository) GetTopActors(ctx context.Context, userID int, year int, limit int) ([]models.ActorStat, error) {
+	rows, err := r.db.QueryContext(ctx, collectedMovies+`
+		SELECT a.id, a.first_name, a.last_name, a.image_url, COUNT(*) AS cnt
+		FROM collected c
+		JOIN movie_cast mc ON mc.movie_id = c.movie_id
+		JOIN actors a ON a.id = mc.actor_id
+		GROUP BY a.id, a.first_name, a.last_name, a.image_url
+		ORDER BY cnt DESC, a.last_name, a.first_name
+		LIMIT $3
+	`, userID, year, limit)
+	if err != nil {
+		r.logger.Error("Failed to get top actors", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]models.ActorStat, 0)
+	for rows.Next() {
+		var s models.ActorStat
+		if err := rows.Scan(&s.ActorID, &s.FirstName, &s.LastName, &s.ImageURL, &s.Count); err != nil {
+			r.logger.Error("Failed to scan actor stat row", err)
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *StatsRepository) GetScoreComparison(ctx context.Context, userID int, year int) (models.ScoreComparison, error) {
+	var favorites, catalogue sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT AVG(m.score)
+			 FROM user_movies um
+			 JOIN movies m ON m.id = um.movie_id
+			 WHERE um.user_id = $1
+			 AND um.relation_type = 'favorite'
+			 AND ($2 = 0 OR EXTRACT(YEAR FROM um.time_added) = $2)),
+			(SELECT AVG(score) FROM movies)
+	`, userID, year).Scan(&favorites, &catalogue)
+	if err != nil {
+		r.logger.Error("Failed to get score comparison", err)
+		return models.ScoreComparison{}, err
+	}
+
+	var scores models.ScoreComparison
+	if favorites.Valid {
+		scores.FavoritesAverage = &favorites.Float64
+	}
+	if catalogue.Valid {
+		scores.CatalogueAverage = &catalogue.Float64
+	}
+	return scores, nil
+}
+
+// GetCollectionGrowth returns movies added per month with a running total.
+// Each movie is counted once, in the month it first entered either list, so
+// the running total matches the distinct movies in CollectionTotals. The
+// total is computed over the whole history before filtering by year, so a
+// yearly view still starts from the size of the collection at that time.
+func (r *StatsRepository) GetCollectionGrowth(ctx context.Context, userID int, year int) ([]models.GrowthPoint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT to_char(month, 'YYYY-MM'), added, cumulative
+		FROM (
+			SELECT month,
+			       COUNT(*) AS added,
+			       SUM(COUNT(*)) OVER (ORDER BY month) AS cumulative
+			FROM (
+				SELECT date_trunc('month', MIN(time_added)) AS month
+				FROM user_movies
+				WHERE user_id = $1 AND time_added IS NOT NULL
+				GROUP BY movie_id
+			) first_added
+			GROUP BY month
+		) growth
+		WHERE ($2 = 0 OR EXTRACT(YEAR FROM month) = $2)
+		ORDER BY month
+	`, userID, year)
+	if err != nil {
+		r.logger.Error("Failed to get collection growth", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]models.GrowthPoint, 0)
+	for rows.Next() {
+		var p models.GrowthPoint
+		if err := rows.Scan(&p.Month, &p.Added, &p.Cumulative); err != nil {
+			r.logger.Error("Failed to scan growth row", err)
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+)
+
+// tracedDB wraps *sql.DB so every context-aware query gets a span named
+// after the repository method that issued it, with the statement attached.
+// The plain Query/Exec methods of the embedded *sql.DB are not traced.
+type tracedDB struct {
+	*sql.DB
+}
+
+func newTracedDB(db *sql.DB) *tracedDB {
+	return &tracedDB{DB: db}
+}
+
+// QueryContext's span stays open until the rows are read to the end or
+// closed, so it covers fetching them, not just the first round trip.
+func (db *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*tracedRows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// QueryRowContext's span ends once the query has run; the single row is
+// read by Scan afterwards.
+func (db *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+func (db *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+// tracedRows ends its query's span when iteration finishes or the rows are
+// closed, whichever comes first, recording how many rows were read and the
+// error that stopped iteration, if any.
+type tracedRows struct {
+	*sql.Rows
+	span  trace.Span
+	count int
+	ended bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	r.end(r.Rows.Err())
+	return false
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	if spanErr := r.Rows.Err(); spanErr != nil {
+		r.end(spanErr)
+	} else {
+		r.end(err)
+	}
+	return err
+}
+
+func (r *tracedRows) end(err error) {
+	if r.ended {
+		return
+	}
+	r.ended = true
+	r.span.SetAttributes(attribute.Int("db.response.returned_rows", r.count))
+	tracing.End(r.span, err)
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	query = strings.Join(strings.Fields(query), " ")
+	operation := query
+	if i := strings.IndexByte(query, ' '); i > 0 {
+		operation = query[:i]
+	}
+	return tracing.Start(ctx, callerName(3),
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.operation.name", strings.ToUpper(operation)),
+		attribute.String("db.query.text", query),
+	)
+}
+
+// callerName returns "Type.Method" for the function skip frames up, so
+// spans read e.g. "MovieRepository.GetMovieByID".
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "postgres.query"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	name = name[strings.LastIndexByte(name, '/')+1:]
+	name = strings.TrimPrefix(name, "postgres.")
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	return name
+}
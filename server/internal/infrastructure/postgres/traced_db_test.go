@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errFetch = errors.New("connection reset while fetching")
+
+// rowsDriver answers every query with three rows of one column, failing
+// after the second when the query is "fail".
+type rowsDriver struct{}
+
+func (rowsDriver) Open(string) (driver.Conn, error) { return rowsConn{}, nil }
+
+type rowsConn struct{}
+
+func (rowsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (rowsConn) Close() error                        { return nil }
+func (rowsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (rowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{fail: query == "fail"}, nil
+}
+
+type fakeRows struct {
+	next int
+	fail bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.fail && r.next == 2 {
+		return errFetch
+	}
+	if r.next == 3 {
+		return io.EOF
+	}
+	r.next++
+	dest[0] = int64(r.next)
+	return nil
+}
+
+func init() {
+	sql.Register("traced-db-test", rowsDriver{})
+}
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestQueryContextSpanCoversFetching(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		rows    int64
+		wantErr bool
+	}{
+		{"all rows read", "SELECT id FROM movies", 3, false},
+		{"error while fetching", "fail", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+			sqlDB, err := sql.Open("traced-db-test", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sqlDB.Close()
+			db := newTracedDB(sqlDB)
+
+			rows, err := db.QueryContext(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(recorder.Ended()); n != 0 {
+				t.Fatalf("span ended before the rows were read (%d ended)", n)
+			}
+			for rows.Next() {
+			}
+			if got := rows.Err() != nil; got != tt.wantErr {
+				t.Fatalf("rows.Err() = %v", rows.Err())
+			}
+			rows.Close()
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if got := attributeValue(span.Attributes(), "db.response.returned_rows"); got.AsInt64() != tt.rows {
+				t.Errorf("returned_rows = %v, want %d", got.Emit(), tt.rows)
+			}
+			if got := span.Status().Code == codes.Error; got != tt.wantErr {
+				t.Errorf("span status = %v, want error %v", span.Status(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func attributeValue(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
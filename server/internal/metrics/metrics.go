@@ -0,0 +1,177 @@
+// Package metrics defines the service's Prometheus collectors on a private
+// registry exposed by Handler. Every method is safe on a nil *Metrics, so
+// components built without metrics (or with them disabled) need no checks.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "movies"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpDuration        *prometheus.HistogramVec
+	httpInFlight        prometheus.Gauge
+	recComputeDuration  *prometheus.HistogramVec
+	recCandidates       prometheus.Histogram
+	backgroundJobErrors *prometheus.CounterVec
+	ssrRenders          *prometheus.CounterVec
+	cacheLookups        *prometheus.CounterVec
+}
+
+// New registers all collectors, plus Go runtime and process metrics, on a
+// fresh registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route pattern, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests currently being served.",
+		}),
+		recComputeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "recommendation_compute_duration_seconds",
+			Help:      "Time spent computing a user's recommendations.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"outcome"}),
+		recCandidates: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "recommendation_candidates",
+			Help:      "Candidate movies scored per recommendation computation.",
+			Buckets:   []float64{0, 10, 25, 50, 100, 200, 350, 500, 1000},
+		}),
+		backgroundJobErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "background_job_failures_total",
+			Help:      "Background jobs that returned an error, by job.",
+		}, []string{"job"}),
+		ssrRenders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ssr_renders_total",
+			Help:      "Server-side rendered pages by page type and client kind (crawler or browser).",
+		}, []string{"page", "client"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Read-through cache lookups by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.httpInFlight,
+		m.recComputeDuration,
+		m.recCandidates,
+		m.backgroundJobErrors,
+		m.ssrRenders,
+		m.cacheLookups,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDB exports the connection pool statistics of db (open, in use,
+// idle, waits, ...) as go_sql_* gauges labelled with dbName.
+func (m *Metrics) RegisterDB(db *sql.DB, dbName string) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// RequestStarted and RequestFinished bracket every HTTP request.
+func (m *Metrics) RequestStarted() {
+	if m == nil {
+		return
+	}
+	m.httpInFlight.Inc()
+}
+
+func (m *Metrics) RequestFinished(route, method string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpInFlight.Dec()
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(route, method, code).Inc()
+	m.httpDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// RecommendationsComputed records one ComputeRecommendations run.
+func (m *Metrics) RecommendationsComputed(duration time.Duration, candidates int, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.recComputeDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+	if err == nil {
+		m.recCandidates.Observe(float64(candidates))
+	}
+}
+
+// BackgroundJobFailed counts a failed fire-and-forget job.
+func (m *Metrics) BackgroundJobFailed(job string) {
+	if m == nil {
+		return
+	}
+	m.backgroundJobErrors.WithLabelValues(job).Inc()
+}
+
+// SSRRendered counts a server-side rendered page.
+func (m *Metrics) SSRRendered(page string, crawler bool) {
+	if m == nil {
+		return
+	}
+	client := "browser"
+	if crawler {
+		client = "crawler"
+	}
+	m.ssrRenders.WithLabelValues(page, client).Inc()
+}
+
+// CacheLookup counts a read-through cache lookup.
+func (m *Metrics) CacheLookup(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
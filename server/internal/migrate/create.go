@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty up/down pair for name in dir, numbered one past the
+// highest existing version, and returns the two paths.
+func Create(dir, name string) ([]string, error) {
+	slug := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return nil, fmt.Errorf("migration name %q has no letters or digits", name)
+	}
+
+	version, err := nextVersion(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%03d_%s.%s.sql", version, slug, direction))
+		body := fmt.Sprintf("-- %s: %s\n", direction, name)
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, err
+		}
+		_, err = f.WriteString(body)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// nextVersion returns the version after the highest one in fsys, or 1 when
+// it has no migrations yet.
+func nextVersion(fsys fs.FS) (int64, error) {
+	existing, err := load(fsys)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) == 0 {
+		return 1, nil
+	}
+	return existing[len(existing)-1].Version + 1, nil
+}
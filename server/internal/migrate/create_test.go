@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want int64
+	}{
+		{"empty directory", sqlFiles(), 1},
+		{"only other files", sqlFiles("migrations.go"), 1},
+		{"after the highest version", sqlFiles("001_init.up.sql", "001_init.down.sql", "002_add_follows.up.sql"), 3},
+		{"gaps are not filled", sqlFiles("001_init.up.sql", "007_add_feeds.up.sql"), 8},
+		{"past three digits", sqlFiles("999_add_feeds.up.sql", "1000_add_archive.up.sql"), 1001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextVersion(tt.fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("nextVersion = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := nextVersion(sqlFiles("001_init.down.sql")); err == nil {
+		t.Error("nextVersion numbered past a migration without an up file")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"001_init.up.sql", "001_init.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Create(dir, "Add user notes (v2)!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "002_add_user_notes_v2.up.sql"),
+		filepath.Join(dir, "002_add_user_notes_v2.down.sql"),
+	}
+	if len(paths) != len(want) || paths[0] != want[0] || paths[1] != want[1] {
+		t.Fatalf("Create wrote %v, want %v", paths, want)
+	}
+	body, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "-- up: Add user notes (v2)!\n" {
+		t.Errorf("up file = %q", body)
+	}
+
+	if _, err := load(os.DirFS(dir)); err != nil {
+		t.Errorf("created files do not load: %v", err)
+	}
+	if _, err := Create(dir, "--- !"); err == nil {
+		t.Error("Create accepted a name without letters or digits")
+	}
+}
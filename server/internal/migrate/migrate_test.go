@@ -0,0 +1,105 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jgamaraalv/movies.git/database/migrations"
+)
+
+func sqlFiles(names ...string) fstest.MapFS {
+	fsys := make(fstest.MapFS, len(names))
+	for _, name := range names {
+		fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1;\n")}
+	}
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	fsys := sqlFiles(
+		"010_add_reviews.up.sql",
+		"002_add_follows.down.sql",
+		"002_add_follows.up.sql",
+		"001_init.up.sql",
+		"1000_add_archive.up.sql",
+		"1000_add_archive.down.sql",
+		// Not migrations: left alone.
+		"migrations.go",
+		"README.md",
+		"003_Add-Tags.up.sql",
+		"004_add_tags.sql",
+		"notes/005_add_notes.up.sql",
+	)
+
+	got, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "init", upFile: "001_init.up.sql"},
+		{Version: 2, Name: "add_follows", upFile: "002_add_follows.up.sql", downFile: "002_add_follows.down.sql"},
+		{Version: 10, Name: "add_reviews", upFile: "010_add_reviews.up.sql"},
+		{Version: 1000, Name: "add_archive", upFile: "1000_add_archive.up.sql", downFile: "1000_add_archive.down.sql"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("loaded %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if s := got[0].String(); s != "001_init" {
+		t.Errorf("String() = %q, want 001_init", s)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "version used by two names",
+			fsys:    sqlFiles("001_init.up.sql", "002_add_follows.up.sql", "002_add_feeds.up.sql"),
+			wantErr: `migration version 2 is used by both "add_feeds" and "add_follows"`,
+		},
+		{
+			name:    "same version written two ways",
+			fsys:    sqlFiles("002_add_follows.up.sql", "2_add_feeds.down.sql"),
+			wantErr: "migration version 2 is used by both",
+		},
+		{
+			name:    "down file without an up file",
+			fsys:    sqlFiles("001_init.up.sql", "002_add_follows.down.sql"),
+			wantErr: "migration 002_add_follows has no up file",
+		},
+		{
+			name:    "version out of range",
+			fsys:    sqlFiles("99999999999999999999_init.up.sql"),
+			wantErr: "migration 99999999999999999999_init.up.sql:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := load(tt.fsys); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLoadEmbedded catches a badly named file in database/migrations before
+// the API refuses to start over it.
+func TestLoadEmbedded(t *testing.T) {
+	got, err := load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 {
+		t.Error("no migrations embedded")
+	}
+}
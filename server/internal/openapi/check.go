@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jgamaraalv/movies.git/internal/handler"
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+// schemaTypes pairs the component schemas with the Go types whose JSON
+// encoding they describe.
+var schemaTypes = map[string]interface{}{
+	"RegisterRequest":   handler.RegisterRequest{},
+	"AuthRequest":       handler.AuthRequest{},
+	"CollectionRequest": handler.CollectionRequest{},
+	"AuthResponse":      handler.AuthResponse{},
+	"FollowResponse":    handler.FollowResponse{},
+	"FeedResponse":      handler.FeedResponse{},
+	"FeedTokenResponse": handler.FeedTokenResponse{},
+	"Problem":           handler.Problem{},
+	"FieldError":        handler.FieldError{},
+	"Movie":             models.Movie{},
+	"Genre":             models.Genre{},
+	"Actor":             models.Actor{},
+	"UserSummary":       models.UserSummary{},
+	"ActivityEvent":     models.ActivityEvent{},
+	"ImportReport":      models.ImportReport{},
+	"ImportRowResult":   models.ImportRowResult{},
+	"UserStats":         models.UserStats{},
+	"CollectionTotals":  models.CollectionTotals{},
+	"GenreStat":         models.GenreStat{},
+	"DecadeStat":        models.DecadeStat{},
+	"ActorStat":         models.ActorStat{},
+	"ScoreComparison":   models.ScoreComparison{},
+	"GrowthPoint":       models.GrowthPoint{},
+	"StatsSummary":      models.StatsSummary{},
+}
+
+var operationMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+	Required   []string                   `json:"required"`
+}
+
+// Check verifies that the document and the server agree: every documented
+// operation must reach one of patterns (the API routes registered on mux),
+// every pattern must be reached by a documented path, and every schema
+// tied to a Go type must list exactly its JSON fields, with the ones that
+// are never omitted marked required.
+func Check(mux *http.ServeMux, patterns []string) error {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return fmt.Errorf("parse openapi.json: %w", err)
+	}
+
+	var problems []error
+	problems = append(problems, checkRoutes(doc, mux, patterns)...)
+	problems = append(problems, checkSchemas(doc)...)
+	return errors.Join(problems...)
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+func checkRoutes(doc document, mux *http.ServeMux, patterns []string) []error {
+	registered := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		registered[p] = false
+	}
+
+	var problems []error
+	for _, path := range sortedKeys(doc.Paths) {
+		// Path parameters in this API are numeric IDs
+		sample := pathParam.ReplaceAllString(path, "1")
+		for _, method := range operationMethods {
+			if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+				continue
+			}
+			req, err := http.NewRequest(method, sample, nil)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s %s: %w", method, path, err))
+				continue
+			}
+			_, pattern := mux.Handler(req)
+			if _, ok := registered[pattern]; !ok {
+				problems = append(problems, fmt.Errorf("%s %s is documented but not routed to the API (matched %q)", method, path, pattern))
+				continue
+			}
+			registered[pattern] = true
+		}
+	}
+
+	for _, p := range patterns {
+		if !registered[p] {
+			problems = append(problems, fmt.Errorf("route %s is not documented", p))
+		}
+	}
+	return problems
+}
+
+func checkSchemas(doc document) []error {
+	var problems []error
+	for _, name := range sortedKeys(schemaTypes) {
+		s, ok := doc.Components.Schemas[name]
+		if !ok {
+			problems = append(problems, fmt.Errorf("schema %s is missing", name))
+			continue
+		}
+
+		fields, required := jsonFields(reflect.TypeOf(schemaTypes[name]))
+		for _, field := range fields {
+			if _, ok := s.Properties[field]; !ok {
+				problems = append(problems, fmt.Errorf("schema %s lacks property %s", name, field))
+			}
+		}
+		for _, prop := range sortedKeys(s.Properties) {
+			if !contains(fields, prop) {
+				problems = append(problems, fmt.Errorf("schema %s documents unknown property %s", name, prop))
+			}
+		}
+		sort.Strings(s.Required)
+		if !reflect.DeepEqual(required, s.Required) && (len(required) > 0 || len(s.Required) > 0) {
+			problems = append(problems, fmt.Errorf("schema %s requires %v, want %v", name, s.Required, required))
+		}
+	}
+	return problems
+}
+
+// jsonFields lists the names encoding/json uses for t's fields, and those
+// of them without omitempty.
+func jsonFields(t reflect.Type) (fields, required []string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(fields)
+	sort.Strings(required)
+	return fields, required
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
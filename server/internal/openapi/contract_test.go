@@ -0,0 +1,356 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/handler"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// The contract tests send requests through the real handlers, backed by
+// in-memory repositories, and validate both sides against the operation
+// openapi.json documents for them.
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	registered map[string]bool
+}
+
+func (f *fakeUserRepository) Register(ctx context.Context, name, email, hashedPassword string) (bool, error) {
+	if f.registered[email] {
+		return false, repository.ErrUserAlreadyExists
+	}
+	f.registered[email] = true
+	return true, nil
+}
+
+type fakeMovieRepository struct {
+	repository.MovieRepository
+	movies []models.Movie
+}
+
+func (f *fakeMovieRepository) GetTopMovies(ctx context.Context) ([]models.Movie, error) {
+	// Listings leave the relations out unless asked for
+	listed := make([]models.Movie, len(f.movies))
+	for i, m := range f.movies {
+		m.Genres, m.Keywords, m.Casting = nil, nil, nil
+		listed[i] = m
+	}
+	return listed, nil
+}
+
+func (f *fakeMovieRepository) GetMovieByID(ctx context.Context, id int) (models.Movie, error) {
+	for _, m := range f.movies {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return models.Movie{}, repository.ErrMovieNotFound
+}
+
+func testMovies() []models.Movie {
+	tagline, overview, language := "Free your mind.", "A hacker learns the truth.", "en"
+	poster, trailer, image := "https://image.tmdb.org/t/p/w500/matrix.jpg", "https://www.youtube.com/watch?v=m8e-FF8MsqU", "https://image.tmdb.org/t/p/w185/keanu.jpg"
+	score, popularity := float32(8.2), float32(85.3)
+	full := models.Movie{
+		ID: 1, TMDB_ID: 603, Title: "The Matrix", Tagline: &tagline, ReleaseYear: 1999,
+		Genres:   []models.Genre{{ID: 1, Name: "Action"}},
+		Overview: &overview, Score: &score, Popularity: &popularity,
+		Keywords: []string{"simulation"}, Language: &language,
+		PosterURL: &poster, TrailerURL: &trailer,
+		Casting: []models.Actor{{ID: 1, FirstName: "Keanu", LastName: "Reeves", ImageURL: &image}},
+	}
+	// Only the required fields
+	sparse := models.Movie{ID: 2, Title: "Untitled", ReleaseYear: 2024, Genres: []models.Genre{}, Keywords: []string{}, Casting: []models.Actor{}}
+	return []models.Movie{full, sparse}
+}
+
+func testLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Options{Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func TestRegisterContract(t *testing.T) {
+	log := testLogger(t)
+	users := &fakeUserRepository{registered: map[string]bool{"taken@example.com": true}}
+	accounts := handler.NewAccountHandler(users, nil, nil, nil,
+		config.AuthConfig{JWTSecret: "0123456789abcdef0123456789abcdef"}, config.RecommendationsConfig{}, nil, log)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/account/register", accounts.Register)
+	c := newContract(t, mux)
+
+	tests := []struct {
+		name       string
+		body       string
+		validBody  bool
+		wantStatus int
+	}{
+		{"registers", `{"name": "Ana", "email": "ana@example.com", "password": "correct-horse-9"}`, true, http.StatusOK},
+		{"missing name", `{"email": "bo@example.com", "password": "correct-horse-9"}`, false, http.StatusBadRequest},
+		{"email taken", `{"name": "Cy", "email": "taken@example.com", "password": "correct-horse-9"}`, true, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.validateRequest(http.MethodPost, "/api/v1/account/register", []byte(tt.body))
+			if tt.validBody && err != nil {
+				t.Fatalf("request body does not match the schema: %v", err)
+			}
+			if !tt.validBody && err == nil {
+				t.Fatal("request body matches the schema, want it rejected")
+			}
+
+			rec := c.do(t, http.MethodPost, "/api/v1/account/register", "/api/v1/account/register", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestMovieContract(t *testing.T) {
+	movies := handler.NewMovieHandler(&fakeMovieRepository{movies: testMovies()}, nil, testLogger(t))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/movies/top", movies.GetTopMovies)
+	mux.HandleFunc("GET /api/v1/movies/{id}", movies.GetMovie)
+	c := newContract(t, mux)
+
+	tests := []struct {
+		name       string
+		path       string
+		target     string
+		wantStatus int
+	}{
+		{"details", "/api/v1/movies/{id}", "/api/v1/movies/1", http.StatusOK},
+		{"details without optional fields", "/api/v1/movies/{id}", "/api/v1/movies/2", http.StatusOK},
+		{"unknown movie", "/api/v1/movies/{id}", "/api/v1/movies/99", http.StatusNotFound},
+		{"invalid id", "/api/v1/movies/{id}", "/api/v1/movies/abc", http.StatusBadRequest},
+		{"listing without relations", "/api/v1/movies/top", "/api/v1/movies/top", http.StatusOK},
+		{"unknown relation", "/api/v1/movies/top", "/api/v1/movies/top?include=reviews", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := c.do(t, http.MethodGet, tt.path, tt.target, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+// contract serves requests through a mux and checks each response against
+// the operation documented for it.
+type contract struct {
+	doc map[string]any
+	mux *http.ServeMux
+}
+
+func newContract(t *testing.T, mux *http.ServeMux) *contract {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return &contract{doc: doc, mux: mux}
+}
+
+// do sends a request to target and fails the test unless the status,
+// content type and body are documented for the operation at path.
+func (c *contract) do(t *testing.T, method, path, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	c.mux.ServeHTTP(rec, req)
+	if err := c.validateResponse(method, path, rec); err != nil {
+		t.Errorf("%s %s: response does not match openapi.json: %v\n%s", method, target, err, rec.Body)
+	}
+	return rec
+}
+
+func (c *contract) operation(method, path string) (map[string]any, error) {
+	op, ok := lookup(c.doc, "paths", path, strings.ToLower(method)).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s %s is not documented", method, path)
+	}
+	return op, nil
+}
+
+func (c *contract) validateRequest(method, path string, body []byte) error {
+	op, err := c.operation(method, path)
+	if err != nil {
+		return err
+	}
+	s, ok := lookup(op, "requestBody", "content", "application/json", "schema").(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s %s documents no JSON request body", method, path)
+	}
+	return c.validateJSON(s, body)
+}
+
+func (c *contract) validateResponse(method, path string, rec *httptest.ResponseRecorder) error {
+	op, err := c.operation(method, path)
+	if err != nil {
+		return err
+	}
+	response, ok := lookup(op, "responses", strconv.Itoa(rec.Code)).(map[string]any)
+	if !ok {
+		return fmt.Errorf("status %d is not documented", rec.Code)
+	}
+	response = c.resolve(response)
+
+	content, _ := response["content"].(map[string]any)
+	if len(content) == 0 {
+		if rec.Body.Len() > 0 {
+			return fmt.Errorf("status %d is documented without a body", rec.Code)
+		}
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("Content-Type %q: %w", rec.Header().Get("Content-Type"), err)
+	}
+	s, ok := lookup(content, mediaType, "schema").(map[string]any)
+	if !ok {
+		return fmt.Errorf("status %d is not documented as %s", rec.Code, mediaType)
+	}
+	return c.validateJSON(s, rec.Body.Bytes())
+}
+
+func (c *contract) validateJSON(s map[string]any, body []byte) error {
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	return c.validate(s, value, "$")
+}
+
+// validate checks value against s. It knows the keywords openapi.json
+// uses: $ref, type, enum, properties, required and items. Properties a
+// schema does not list are reported, so undocumented fields are caught.
+func (c *contract) validate(s map[string]any, value any, at string) error {
+	s = c.resolve(s)
+
+	if types := schemaTypesOf(s); len(types) > 0 && !slices.Contains(types, jsonType(value)) {
+		// Integers are numbers too
+		if !(jsonType(value) == "integer" && slices.Contains(types, "number")) {
+			return fmt.Errorf("%s: %s, want %s", at, jsonType(value), strings.Join(types, " or "))
+		}
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.Contains(enum, any(fmt.Sprint(value))) {
+		return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := s["properties"].(map[string]any)
+		for _, name := range sortedKeys(v) {
+			p, ok := properties[name].(map[string]any)
+			if !ok {
+				if properties != nil {
+					return fmt.Errorf("%s: undocumented property %s", at, name)
+				}
+				continue
+			}
+			if err := c.validate(p, v[name], at+"."+name); err != nil {
+				return err
+			}
+		}
+		required, _ := s["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %s", at, name)
+			}
+		}
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := c.validate(items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows a local $ref, such as #/components/schemas/Movie.
+func (c *contract) resolve(s map[string]any) map[string]any {
+	for {
+		ref, ok := s["$ref"].(string)
+		if !ok {
+			return s
+		}
+		path := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+		s, _ = lookup(c.doc, path...).(map[string]any)
+	}
+}
+
+func lookup(node any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+func schemaTypesOf(s map[string]any) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, name := range t {
+			types = append(types, name.(string))
+		}
+		sort.Strings(types)
+		return types
+	}
+	return nil
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
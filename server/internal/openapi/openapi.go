@@ -0,0 +1,49 @@
+// Package openapi serves the OpenAPI 3.1 description of the JSON API and a
+// docs page rendering it. The document is written by hand and embedded;
+// Check, run by the cmd/api tests, keeps it honest against the routes
+// actually registered and the Go types the handlers encode.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// Spec returns the embedded OpenAPI document.
+func Spec() []byte {
+	return spec
+}
+
+// Handler serves the document for client generators.
+func Handler() http.Handler {
+	return staticHandler("application/json", spec)
+}
+
+// DocsCSP is the Content-Security-Policy the docs page needs: Redoc comes
+// from its CDN, styles itself inline and runs its search in a blob worker.
+const DocsCSP = "default-src 'none'; " +
+	"script-src https://cdn.redoc.ly; " +
+	"style-src 'unsafe-inline' https://fonts.googleapis.com; " +
+	"font-src https://fonts.gstatic.com; " +
+	"img-src 'self' data: https://cdn.redoc.ly; " +
+	"worker-src blob:; connect-src 'self'; " +
+	"base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// DocsHandler serves an HTML page that renders the document.
+func DocsHandler() http.Handler {
+	return staticHandler("text/html; charset=utf-8", docsPage)
+}
+
+func staticHandler(contentType string, body []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(body)
+	})
+}
@@ -0,0 +1,259 @@
+// Package ssr renders the server-side pages with html/template. Pages fill
+// the blocks of a layout wrapped around the frontend's index.html and share
+// partials such as movie-item. Templates are parsed once; with reload on,
+// they are parsed again whenever they or index.html change on disk.
+package ssr
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+)
+
+//go:embed templates/*.gohtml
+var embedded embed.FS
+
+const (
+	layoutName   = "layout"
+	partialsGlob = "_*.gohtml"
+)
+
+// Page is what a handler hands to a page template.
+type Page struct {
+	Title       string
+	Description string
+	Meta
+	// Nonce is the request's CSP nonce, carried by the inline scripts.
+	Nonce string
+	// Data is what the page template renders; it is also embedded as JSON
+	// for the frontend to hydrate from.
+	Data any
+}
+
+// Meta describes a page to search engines and link previews: the canonical
+// link, Open Graph and Twitter card tags and a JSON-LD block.
+type Meta struct {
+	// Canonical is the absolute URL of the page.
+	Canonical string
+	// Image is the absolute URL of the picture previewing the page.
+	Image string
+	// Type is the Open Graph type; "website" when empty.
+	Type string
+	// StructuredData is encoded as schema.org JSON-LD when set.
+	StructuredData any
+}
+
+// view is the value every template executes with.
+type view struct {
+	Page
+	Shell shell
+	// Hydration is the JSON the frontend reads from <script id="ssr-data">.
+	Hydration any
+}
+
+// Renderer executes the page templates. It is safe for concurrent use.
+type Renderer struct {
+	indexPath string
+	templates fs.FS
+	// templateDir is empty when the templates are the embedded copy, which
+	// never changes.
+	templateDir string
+	reload      bool
+
+	mu      sync.RWMutex
+	index   []byte
+	shell   shell
+	pages   map[string]*template.Template
+	modTime time.Time
+}
+
+// NewRenderer parses index.html from publicDir and the page templates, from
+// cfg.TemplateDir or the copy built into the binary.
+func NewRenderer(publicDir string, cfg config.SSRConfig) (*Renderer, error) {
+	r := &Renderer{
+		indexPath:   filepath.Join(publicDir, "index.html"),
+		templateDir: cfg.TemplateDir,
+		reload:      cfg.Reload,
+	}
+	if cfg.TemplateDir != "" {
+		r.templates = os.DirFS(cfg.TemplateDir)
+	} else {
+		sub, err := fs.Sub(embedded, "templates")
+		if err != nil {
+			return nil, err
+		}
+		r.templates = sub
+	}
+
+	modTime, err := r.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Render executes the named page into w. The page is rendered in full
+// before anything is written, so a failing template leaves w untouched.
+func (r *Renderer) Render(w io.Writer, name string, page Page) error {
+	if err := r.reloadIfChanged(); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	tmpl, ok := r.pages[name]
+	v := view{
+		Page:      page,
+		Shell:     r.shell,
+		Hydration: map[string]any{"pageType": name, "data": page.Data},
+	}
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("ssr: no page template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, layoutName, v); err != nil {
+		return fmt.Errorf("ssr: render %s: %w", name, err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Index returns index.html as is, for the SPA to render, and when it last
+// changed. A template that fails to reload is reported by Render only; the
+// SPA keeps being served from what was loaded last.
+func (r *Renderer) Index() ([]byte, time.Time) {
+	r.reloadIfChanged()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.index, r.modTime
+}
+
+func (r *Renderer) reloadIfChanged() error {
+	if !r.reload {
+		return nil
+	}
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	changed := modTime.After(r.modTime)
+	r.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	return r.load(modTime)
+}
+
+// lastModified is the latest modification time of index.html and, when
+// they are read from disk, the templates.
+func (r *Renderer) lastModified() (time.Time, error) {
+	info, err := os.Stat(r.indexPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ssr: %w", err)
+	}
+	latest := info.ModTime()
+	if r.templateDir == "" {
+		return latest, nil
+	}
+
+	entries, err := os.ReadDir(r.templateDir)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ssr: %w", err)
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("ssr: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// load parses index.html and every page template, replacing what was loaded
+// before only if all of them parse.
+func (r *Renderer) load(modTime time.Time) error {
+	index, err := os.ReadFile(r.indexPath)
+	if err != nil {
+		return fmt.Errorf("ssr: read page template: %w", err)
+	}
+	sh, err := parseShell(index)
+	if err != nil {
+		return fmt.Errorf("ssr: %w", err)
+	}
+	pages, err := parsePages(r.templates)
+	if err != nil {
+		return fmt.Errorf("ssr: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.index, r.shell, r.pages, r.modTime = index, sh, pages, modTime
+	return nil
+}
+
+// parsePages parses layout.gohtml and the partials (files starting with _)
+// once, then each remaining file as a page on a copy of them, named after
+// the file.
+func parsePages(templates fs.FS) (map[string]*template.Template, error) {
+	base, err := template.New(layoutName).Funcs(funcs).ParseFS(templates, layoutName+".gohtml", partialsGlob)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := fs.Glob(templates, "*.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*template.Template)
+	for _, file := range files {
+		if file == layoutName+".gohtml" || strings.HasPrefix(file, "_") {
+			continue
+		}
+		page, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := page.ParseFS(templates, file); err != nil {
+			return nil, err
+		}
+		pages[strings.TrimSuffix(path.Base(file), ".gohtml")] = page
+	}
+	return pages, nil
+}
+
+var funcs = template.FuncMap{
+	"deref": deref,
+}
+
+// deref returns what a pointer field points to, or nil, so templates can
+// test and print optional values such as a movie's tagline or score.
+func deref(v any) any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer {
+		return v
+	}
+	if rv.IsNil() {
+		return nil
+	}
+	return rv.Elem().Interface()
+}
@@ -0,0 +1,251 @@
+package ssr
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jgamaraalv/movies.git/internal/config"
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden with the rendered pages")
+
+// pageData has the fields of handler.PageData the templates read.
+type pageData struct {
+	TopMovies    []models.Movie `json:"topMovies,omitempty"`
+	RandomMovies []models.Movie `json:"randomMovies,omitempty"`
+	Movies       []models.Movie `json:"movies,omitempty"`
+	Movie        *models.Movie  `json:"movie,omitempty"`
+	Genres       []models.Genre `json:"genres,omitempty"`
+	Query        string         `json:"query,omitempty"`
+	Order        string         `json:"order,omitempty"`
+	Genre        string         `json:"genre,omitempty"`
+	Orders       []order        `json:"-"`
+}
+
+type order struct {
+	Value string
+	Label string
+}
+
+func ptr[T any](v T) *T { return &v }
+
+var (
+	drama  = models.Genre{ID: 18, Name: "Drama"}
+	scifi  = models.Genre{ID: 878, Name: "Science Fiction"}
+	matrix = models.Movie{
+		ID:          603,
+		Title:       "The Matrix",
+		Tagline:     ptr("Welcome to the Real World."),
+		ReleaseYear: 1999,
+		Genres:      []models.Genre{scifi},
+		Overview:    ptr(`Neo learns that "reality" is a simulation <run by machines>.`),
+		Score:       ptr(float32(8.2)),
+		Popularity:  ptr(float32(85.25)),
+		Keywords:    []string{"simulation"},
+		PosterURL:   ptr("https://image.tmdb.org/t/p/w500/matrix.jpg"),
+		TrailerURL:  ptr("https://www.youtube.com/watch?v=m8e-FF8MsqU"),
+		Casting: []models.Actor{
+			{ID: 1, FirstName: "Keanu", LastName: "Reeves", ImageURL: ptr("https://image.tmdb.org/t/p/w185/keanu.jpg")},
+			{ID: 2, FirstName: "Carrie-Anne", LastName: "Moss"},
+		},
+	}
+	// A movie with none of the optional fields
+	untitled = models.Movie{ID: 7, Title: "Untitled & Unknown", ReleaseYear: 2024}
+)
+
+func TestRenderGolden(t *testing.T) {
+	r, err := NewRenderer("testdata", config.SSRConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		golden string
+		page   string
+		data   Page
+	}{
+		{
+			golden: "home",
+			page:   "home",
+			data: Page{
+				Title:       "Movies - Discover Top Films",
+				Description: "Discover the top movies and find something great to watch today",
+				Meta: Meta{
+					Canonical:      "https://movies.example.com/",
+					StructuredData: map[string]any{"@context": "https://schema.org", "@type": "WebSite", "name": "Moovies"},
+				},
+				Nonce: "bm9uY2U",
+				Data:  pageData{TopMovies: []models.Movie{matrix, untitled}, RandomMovies: []models.Movie{untitled}},
+			},
+		},
+		{
+			golden: "movies-search",
+			page:   "movies",
+			data: Page{
+				Title: "'matrix' movies",
+				Meta:  Meta{Canonical: "https://movies.example.com/movies?q=matrix"},
+				Data: pageData{
+					Movies: []models.Movie{matrix},
+					Genres: []models.Genre{drama, scifi},
+					Query:  "matrix",
+					Order:  "score",
+					Genre:  "878",
+					Orders: []order{{"popularity", "Sort by Popularity"}, {"score", "Sort by Score"}},
+				},
+			},
+		},
+		{
+			golden: "movie-details",
+			page:   "movie-details",
+			data: Page{
+				Title:       "The Matrix - Welcome to the Real World.",
+				Description: *matrix.Overview,
+				Meta: Meta{
+					Canonical: "https://movies.example.com/movies/603",
+					Image:     *matrix.PosterURL,
+					Type:      "video.movie",
+					StructuredData: map[string]any{
+						"@context": "https://schema.org", "@type": "Movie", "name": "The Matrix",
+						// </script> in the data must not end the JSON-LD block
+						"description": "</script><script>alert(1)</script>",
+					},
+				},
+				Nonce: "bm9uY2U",
+				Data:  pageData{Movie: &matrix},
+			},
+		},
+		{
+			golden: "movie-details-sparse",
+			page:   "movie-details",
+			data: Page{
+				Title: untitled.Title,
+				Meta:  Meta{Canonical: "https://movies.example.com/movies/7", Type: "video.movie"},
+				Data:  pageData{Movie: &untitled},
+			},
+		},
+		{
+			golden: "not-found",
+			page:   "not-found",
+			data: Page{
+				Title:       "Page not found",
+				Description: "The page you are looking for does not exist or has been removed.",
+				Data:        pageData{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := r.Render(&buf, tt.page, tt.data); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", tt.golden+".golden")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test ./internal/ssr -update to create it)", err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s differs from the rendered page (run go test ./internal/ssr -update if the change is intended):\n%s",
+					path, firstDifference(got, string(want)))
+			}
+		})
+	}
+}
+
+// firstDifference shows the line where got and want first differ.
+func firstDifference(got, want string) string {
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(want, "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			return "line " + strconv.Itoa(i+1) + ":\n  got:  " + g + "\n  want: " + w
+		}
+	}
+	return ""
+}
+
+func TestRenderUnknownPage(t *testing.T) {
+	r, err := NewRenderer("testdata", config.SSRConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := r.Render(&buf, "profile", Page{}); err == nil {
+		t.Error("rendering a page without a template succeeded")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("a failed render wrote %d bytes", buf.Len())
+	}
+}
+
+func TestParseShellRequiresMarkers(t *testing.T) {
+	index, err := os.ReadFile(filepath.Join("testdata", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseShell(index); err != nil {
+		t.Fatalf("parseShell(testdata/index.html) = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		remove  []string
+		wantErr string
+	}{
+		{"no title", []string{"<TITLE data-default>Moovies</TITLE>"}, "no <title>"},
+		{"unclosed title", []string{"</TITLE>"}, "no <title>"},
+		{"no head end", []string{"</head>"}, "no </head>"},
+		{"no main", []string{`<main class="page">`, "</main>"}, "no <main>"},
+		{"unclosed main", []string{"</main>"}, "no <main>"},
+		{"no body end", []string{"</body>"}, "no </body>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := string(index)
+			for _, s := range tt.remove {
+				if !strings.Contains(broken, s) {
+					t.Fatalf("testdata/index.html has no %s", s)
+				}
+				broken = strings.Replace(broken, s, "", 1)
+			}
+			_, err := parseShell([]byte(broken))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseShell() = %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRendererFailsOnBrokenIndex(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewRenderer(dir, config.SSRConfig{}); err == nil {
+		t.Error("NewRenderer succeeded without index.html")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html><body><div id=app></div></body></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewRenderer(dir, config.SSRConfig{})
+	if err == nil || !strings.Contains(err.Error(), "no <title>") {
+		t.Errorf("NewRenderer() = %v, want it to name the missing <title>", err)
+	}
+}
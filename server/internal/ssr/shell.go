@@ -0,0 +1,92 @@
+package ssr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// shell is index.html cut around the parts a page replaces or adds to: the
+// title, the end of the head, the contents of <main> and the end of the
+// body. The frontend build owns index.html (its asset URLs are hashed), so
+// the layout embeds these pieces verbatim instead of copying the markup.
+type shell struct {
+	// BeforeTitle runs up to <title>.
+	BeforeTitle template.HTML
+	// Head runs from after </title> up to </head>.
+	Head template.HTML
+	// BeforeMain runs from </head> through the <main> start tag.
+	BeforeMain template.HTML
+	// AfterMain runs from </main> up to </body>.
+	AfterMain template.HTML
+	// End is </body> and what follows it.
+	End template.HTML
+}
+
+// parseShell finds the cut points by tokenizing index, so attributes,
+// whitespace and case do not matter. It fails, rather than rendering a
+// page without content, when one of the elements is missing.
+func parseShell(index []byte) (shell, error) {
+	var (
+		titleStart, titleEnd = -1, -1
+		headEnd, mainStart   = -1, -1
+		mainEnd, bodyEnd     = -1, -1
+	)
+
+	z := html.NewTokenizer(bytes.NewReader(index))
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return shell{}, fmt.Errorf("tokenize index.html: %w", err)
+			}
+			break
+		}
+		start := offset
+		offset += len(z.Raw())
+
+		name, _ := z.TagName()
+		switch a := atom.Lookup(name); {
+		case tt == html.StartTagToken && a == atom.Title && titleStart < 0:
+			titleStart = start
+		case tt == html.EndTagToken && a == atom.Title && titleEnd < 0:
+			titleEnd = offset
+		case tt == html.EndTagToken && a == atom.Head && headEnd < 0:
+			headEnd = start
+		case tt == html.StartTagToken && a == atom.Main && mainStart < 0:
+			mainStart = offset
+		case tt == html.EndTagToken && a == atom.Main && mainEnd < 0:
+			mainEnd = start
+		case tt == html.EndTagToken && a == atom.Body:
+			bodyEnd = start
+		}
+	}
+
+	for _, part := range []struct {
+		name  string
+		found bool
+	}{
+		{"<title>", titleStart >= 0 && titleEnd > titleStart},
+		{"</head>", headEnd >= titleEnd},
+		{"<main>", mainStart > headEnd && mainEnd >= mainStart},
+		{"</body>", bodyEnd >= mainEnd},
+	} {
+		if !part.found {
+			return shell{}, fmt.Errorf("index.html: no %s where the page layout expects it", part.name)
+		}
+	}
+
+	return shell{
+		BeforeTitle: template.HTML(index[:titleStart]),
+		Head:        template.HTML(index[titleEnd:headEnd]),
+		BeforeMain:  template.HTML(index[headEnd:mainStart]),
+		AfterMain:   template.HTML(index[mainEnd:bodyEnd]),
+		End:         template.HTML(index[bodyEnd:]),
+	}, nil
+}
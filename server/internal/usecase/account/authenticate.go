@@ -1,8 +1,11 @@
 package account
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 	"github.com/jgamaraalv/movies.git/pkg/token"
@@ -20,18 +23,23 @@ type AuthenticateOutput struct {
 }
 
 type AuthenticateUseCase struct {
-	userRepo repository.UserRepository
-	logger   *logger.Logger
+	userRepo  repository.UserRepository
+	jwtSecret string
+	logger    *logger.Logger
 }
 
-func NewAuthenticateUseCase(repo repository.UserRepository, log *logger.Logger) *AuthenticateUseCase {
+func NewAuthenticateUseCase(repo repository.UserRepository, jwtSecret string, log *logger.Logger) *AuthenticateUseCase {
 	return &AuthenticateUseCase{
-		userRepo: repo,
-		logger:   log,
+		userRepo:  repo,
+		jwtSecret: jwtSecret,
+		logger:    log,
 	}
 }
 
-func (uc *AuthenticateUseCase) Execute(input AuthenticateInput) (*AuthenticateOutput, error) {
+func (uc *AuthenticateUseCase) Execute(ctx context.Context, input AuthenticateInput) (*AuthenticateOutput, error) {
+	ctx, span := tracing.Start(ctx, "AuthenticateUseCase.Execute")
+	defer span.End()
+
 	email, err := valueobject.NewEmail(input.Email)
 	if err != nil {
 		return nil, err
@@ -41,17 +49,18 @@ func (uc *AuthenticateUseCase) Execute(input AuthenticateInput) (*AuthenticateOu
 		return nil, valueobject.ErrEmptyPassword
 	}
 
-	success, err := uc.userRepo.Authenticate(email.String(), input.Password)
+	success, err := uc.userRepo.Authenticate(ctx, email.String(), input.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	jwt := token.CreateJWT(
 		models.User{Email: email.String()},
+		uc.jwtSecret,
 		*uc.logger,
 	)
 
-	uc.logger.Info("User authenticated successfully: " + email.String())
+	uc.logger.Info("User authenticated successfully", "email", email.String())
 
 	return &AuthenticateOutput{
 		Success: success,
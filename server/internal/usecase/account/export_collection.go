@@ -0,0 +1,62 @@
+package account
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/entity"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type ExportCollectionInput struct {
+	Email string
+	// Collection limits the export to one collection; empty exports all.
+	Collection string
+	// Write receives each entry in turn so callers can stream the export.
+	Write func(models.CollectionEntry) error
+}
+
+type ExportCollectionOutput struct {
+	Count int
+}
+
+type ExportCollectionUseCase struct {
+	userRepo repository.UserRepository
+	logger   *logger.Logger
+}
+
+func NewExportCollectionUseCase(repo repository.UserRepository, log *logger.Logger) *ExportCollectionUseCase {
+	return &ExportCollectionUseCase{
+		userRepo: repo,
+		logger:   log,
+	}
+}
+
+func (uc *ExportCollectionUseCase) Execute(ctx context.Context, input ExportCollectionInput) (*ExportCollectionOutput, error) {
+	ctx, span := tracing.Start(ctx, "ExportCollectionUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Collection != "" && input.Collection != entity.CollectionFavorites && input.Collection != entity.CollectionWatchlist {
+		return nil, repository.ErrInvalidCollectionType
+	}
+
+	count := 0
+	err = uc.userRepo.StreamCollection(ctx, email.String(), input.Collection, func(entry models.CollectionEntry) error {
+		count++
+		return input.Write(entry)
+	})
+	if err != nil {
+		uc.logger.Error("Failed to export user collection", err)
+		return nil, err
+	}
+
+	return &ExportCollectionOutput{Count: count}, nil
+}
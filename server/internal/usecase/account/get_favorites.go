@@ -1,15 +1,20 @@
 package account
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/entity"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
 type GetFavoritesInput struct {
 	Email string
+	// Include lists relations to load onto every movie.
+	Include []repository.MovieRelation
 }
 
 type FavoriteMovieInfo struct {
@@ -31,28 +36,41 @@ type GetFavoritesOutput struct {
 }
 
 type GetFavoritesUseCase struct {
-	userRepo repository.UserRepository
-	logger   *logger.Logger
+	userRepo  repository.UserRepository
+	movieRepo repository.MovieRepository
+	logger    *logger.Logger
 }
 
-func NewGetFavoritesUseCase(repo repository.UserRepository, log *logger.Logger) *GetFavoritesUseCase {
+func NewGetFavoritesUseCase(repo repository.UserRepository, movieRepo repository.MovieRepository, log *logger.Logger) *GetFavoritesUseCase {
 	return &GetFavoritesUseCase{
-		userRepo: repo,
-		logger:   log,
+		userRepo:  repo,
+		movieRepo: movieRepo,
+		logger:    log,
 	}
 }
 
-func (uc *GetFavoritesUseCase) Execute(input GetFavoritesInput) (*GetFavoritesOutput, error) {
+func (uc *GetFavoritesUseCase) Execute(ctx context.Context, input GetFavoritesInput) (*GetFavoritesOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetFavoritesUseCase.Execute")
+	defer span.End()
+
 	email, err := valueobject.NewEmail(input.Email)
 	if err != nil {
 		return nil, err
 	}
+	if err := repository.ValidateMovieRelations(input.Include); err != nil {
+		return nil, err
+	}
 
-	userModel, err := uc.userRepo.GetAccountDetails(email.String())
+	userModel, err := uc.userRepo.GetAccountDetails(ctx, email.String())
 	if err != nil {
 		uc.logger.Error("Failed to get user favorites", err)
 		return nil, err
 	}
+	if len(input.Include) > 0 {
+		if err := uc.movieRepo.LoadRelations(ctx, userModel.Favorites, input.Include); err != nil {
+			return nil, err
+		}
+	}
 
 	favoritesInfo := make([]FavoriteMovieInfo, len(userModel.Favorites))
 	highlyRatedCount := 0
@@ -79,7 +97,7 @@ func (uc *GetFavoritesUseCase) Execute(input GetFavoritesInput) (*GetFavoritesOu
 		}
 	}
 
-	uc.logger.Info("Successfully retrieved favorites for: " + email.String())
+	uc.logger.Info("Successfully retrieved favorites", "email", email.String())
 
 	return &GetFavoritesOutput{
 		Favorites:        userModel.Favorites,
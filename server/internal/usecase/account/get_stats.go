@@ -0,0 +1,105 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+const (
+	topActorsLimit = 10
+	minStatsYear   = 1900
+)
+
+type GetStatsInput struct {
+	Email string
+	// Year restricts the report to movies added that year; 0 means all time.
+	Year int
+}
+
+type GetStatsOutput struct {
+	Stats models.UserStats
+}
+
+type GetStatsUseCase struct {
+	statsRepo repository.StatsRepository
+	logger    *logger.Logger
+}
+
+func NewGetStatsUseCase(repo repository.StatsRepository, log *logger.Logger) *GetStatsUseCase {
+	return &GetStatsUseCase{
+		statsRepo: repo,
+		logger:    log,
+	}
+}
+
+func (uc *GetStatsUseCase) Execute(ctx context.Context, input GetStatsInput) (*GetStatsOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetStatsUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Year != 0 && (input.Year < minStatsYear || input.Year > time.Now().Year()) {
+		return nil, repository.ErrInvalidStatsYear
+	}
+
+	userID, err := uc.statsRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for stats", err)
+		return nil, err
+	}
+
+	stats := models.UserStats{Year: input.Year}
+
+	if stats.Totals, err = uc.statsRepo.GetCollectionTotals(ctx, userID, input.Year); err != nil {
+		return nil, err
+	}
+	if stats.GenreDistribution, err = uc.statsRepo.GetGenreDistribution(ctx, userID, input.Year); err != nil {
+		return nil, err
+	}
+	if stats.FavoriteDecades, err = uc.statsRepo.GetDecadeDistribution(ctx, userID, input.Year); err != nil {
+		return nil, err
+	}
+	if stats.TopActors, err = uc.statsRepo.GetTopActors(ctx, userID, input.Year, topActorsLimit); err != nil {
+		return nil, err
+	}
+	if stats.Scores, err = uc.statsRepo.GetScoreComparison(ctx, userID, input.Year); err != nil {
+		return nil, err
+	}
+	if stats.Growth, err = uc.statsRepo.GetCollectionGrowth(ctx, userID, input.Year); err != nil {
+		return nil, err
+	}
+
+	stats.Summary = summarizeStats(stats)
+
+	return &GetStatsOutput{Stats: stats}, nil
+}
+
+// summarizeStats picks the highlights of a report. Distributions already come
+// sorted by count, so the top entries are the first ones.
+func summarizeStats(stats models.UserStats) models.StatsSummary {
+	var summary models.StatsSummary
+	if len(stats.GenreDistribution) > 0 {
+		summary.TopGenre = &stats.GenreDistribution[0]
+	}
+	if len(stats.FavoriteDecades) > 0 {
+		summary.TopDecade = &stats.FavoriteDecades[0]
+	}
+	if len(stats.TopActors) > 0 {
+		summary.TopActor = &stats.TopActors[0]
+	}
+	for i := range stats.Growth {
+		if summary.BusiestMonth == nil || stats.Growth[i].Added > summary.BusiestMonth.Added {
+			summary.BusiestMonth = &stats.Growth[i]
+		}
+	}
+	return summary
+}
@@ -1,15 +1,20 @@
 package account
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/entity"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
 type GetWatchlistInput struct {
 	Email string
+	// Include lists relations to load onto every movie.
+	Include []repository.MovieRelation
 }
 
 type WatchlistMovieInfo struct {
@@ -31,28 +36,41 @@ type GetWatchlistOutput struct {
 }
 
 type GetWatchlistUseCase struct {
-	userRepo repository.UserRepository
-	logger   *logger.Logger
+	userRepo  repository.UserRepository
+	movieRepo repository.MovieRepository
+	logger    *logger.Logger
 }
 
-func NewGetWatchlistUseCase(repo repository.UserRepository, log *logger.Logger) *GetWatchlistUseCase {
+func NewGetWatchlistUseCase(repo repository.UserRepository, movieRepo repository.MovieRepository, log *logger.Logger) *GetWatchlistUseCase {
 	return &GetWatchlistUseCase{
-		userRepo: repo,
-		logger:   log,
+		userRepo:  repo,
+		movieRepo: movieRepo,
+		logger:    log,
 	}
 }
 
-func (uc *GetWatchlistUseCase) Execute(input GetWatchlistInput) (*GetWatchlistOutput, error) {
+func (uc *GetWatchlistUseCase) Execute(ctx context.Context, input GetWatchlistInput) (*GetWatchlistOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetWatchlistUseCase.Execute")
+	defer span.End()
+
 	email, err := valueobject.NewEmail(input.Email)
 	if err != nil {
 		return nil, err
 	}
+	if err := repository.ValidateMovieRelations(input.Include); err != nil {
+		return nil, err
+	}
 
-	userModel, err := uc.userRepo.GetAccountDetails(email.String())
+	userModel, err := uc.userRepo.GetAccountDetails(ctx, email.String())
 	if err != nil {
 		uc.logger.Error("Failed to get user watchlist", err)
 		return nil, err
 	}
+	if len(input.Include) > 0 {
+		if err := uc.movieRepo.LoadRelations(ctx, userModel.Watchlist, input.Include); err != nil {
+			return nil, err
+		}
+	}
 
 	watchlistInfo := make([]WatchlistMovieInfo, len(userModel.Watchlist))
 	highlyRatedCount := 0
@@ -79,7 +97,7 @@ func (uc *GetWatchlistUseCase) Execute(input GetWatchlistInput) (*GetWatchlistOu
 		}
 	}
 
-	uc.logger.Info("Successfully retrieved watchlist for: " + email.String())
+	uc.logger.Info("Successfully retrieved watchlist", "email", email.String())
 
 	return &GetWatchlistOutput{
 		Watchlist:        userModel.Watchlist,
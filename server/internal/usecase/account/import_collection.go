@@ -0,0 +1,421 @@
+package account
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/entity"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+const (
+	// FavoriteRatingThreshold is the minimum rating (10-point scale) for a
+	// rated movie to be imported as a favorite.
+	FavoriteRatingThreshold = 8.0
+
+	// fuzzyMatchThreshold is the minimum title similarity for a fuzzy match,
+	// and fuzzyAmbiguityMargin how close the runner-up must be to make the
+	// match ambiguous instead.
+	fuzzyMatchThreshold  = 0.85
+	fuzzyAmbiguityMargin = 0.05
+
+	// fuzzyCandidateLimit caps the movies sharing a word with a title that
+	// has no year, which a fuzzy match is then picked from.
+	fuzzyCandidateLimit = 200
+	// minTitleWordLength leaves words like "of" out of the candidate search.
+	minTitleWordLength = 3
+
+	maxImportRows = 20000
+)
+
+const (
+	MatchedByTMDBID    = "tmdb_id"
+	MatchedByTitleYear = "title_year"
+	MatchedByTitle     = "title"
+	MatchedByFuzzy     = "fuzzy"
+)
+
+type ImportCollectionInput struct {
+	Email  string
+	Rows   []models.ImportRow
+	Commit bool
+}
+
+type ImportCollectionOutput struct {
+	Report models.ImportReport
+}
+
+type ImportCollectionUseCase struct {
+	userRepo  repository.UserRepository
+	movieRepo repository.MovieRepository
+	logger    *logger.Logger
+}
+
+func NewImportCollectionUseCase(userRepo repository.UserRepository, movieRepo repository.MovieRepository, log *logger.Logger) *ImportCollectionUseCase {
+	return &ImportCollectionUseCase{
+		userRepo:  userRepo,
+		movieRepo: movieRepo,
+		logger:    log,
+	}
+}
+
+func (uc *ImportCollectionUseCase) Execute(ctx context.Context, input ImportCollectionInput) (*ImportCollectionOutput, error) {
+	ctx, span := tracing.Start(ctx, "ImportCollectionUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.Rows) > maxImportRows {
+		return nil, repository.ErrTooManyImportRows
+	}
+
+	matcher, err := uc.newMatcher(ctx, input.Rows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.ImportReport{
+		DryRun:    !input.Commit,
+		Matched:   make([]models.ImportRowResult, 0),
+		Ambiguous: make([]models.ImportRowResult, 0),
+		Unmatched: make([]models.ImportRowResult, 0),
+		Skipped:   make([]models.ImportRowResult, 0),
+	}
+
+	items := make([]models.CollectionItem, 0)
+	seen := make(map[string]bool)
+
+	for _, row := range input.Rows {
+		result := models.ImportRowResult{
+			Line:  row.Line,
+			List:  row.List,
+			Title: row.Title,
+			Year:  row.Year,
+		}
+
+		collection, skipReason := collectionForRow(row)
+		result.Collection = collection
+
+		match, err := matcher.match(ctx, row)
+		if err != nil {
+			uc.logger.Error("Failed to match import row", err, "line", row.Line)
+			return nil, err
+		}
+
+		switch {
+		case skipReason != "":
+			// Still matched, so the report shows which movie was left out
+			result.Movie = match.movie
+			result.MatchedBy = match.by
+			result.Similarity = match.similarity
+			result.Candidates = match.candidates
+			result.Reason = skipReason
+			report.Skipped = append(report.Skipped, result)
+		case match.movie != nil:
+			result.Movie = match.movie
+			result.MatchedBy = match.by
+			result.Similarity = match.similarity
+			report.Matched = append(report.Matched, result)
+
+			key := strconv.Itoa(match.movie.ID) + ":" + collection
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			item := models.CollectionItem{MovieID: match.movie.ID, Collection: collection}
+			if row.Date != nil {
+				item.TimeAdded = *row.Date
+			}
+			items = append(items, item)
+		case len(match.candidates) > 0:
+			result.Candidates = match.candidates
+			result.Reason = "multiple movies match this title"
+			report.Ambiguous = append(report.Ambiguous, result)
+		default:
+			result.Reason = "no matching movie in catalogue"
+			report.Unmatched = append(report.Unmatched, result)
+		}
+	}
+
+	if input.Commit && len(items) > 0 {
+		imported, err := uc.userRepo.SaveCollectionItems(ctx, email.String(), items)
+		if err != nil {
+			uc.logger.Error("Failed to save imported collection", err)
+			return nil, err
+		}
+		report.Imported = imported
+		uc.logger.Info("Imported movies", "email", email.String(), "count", imported)
+	}
+
+	return &ImportCollectionOutput{Report: report}, nil
+}
+
+// collectionForRow decides which collection an export row belongs to. Rows
+// that have no equivalent collection, such as watch history and ratings
+// below the favorite threshold, get a reason instead and are reported as
+// skipped.
+func collectionForRow(row models.ImportRow) (string, string) {
+	if row.TitleType != "" && !isMovieTitleType(row.TitleType) {
+		return "", "not a movie (" + row.TitleType + ")"
+	}
+
+	if row.Collection == entity.CollectionFavorites || row.Collection == entity.CollectionWatchlist {
+		return row.Collection, ""
+	}
+
+	switch row.List {
+	case "watchlist":
+		return entity.CollectionWatchlist, ""
+	case "ratings":
+		if row.Rating == nil {
+			return "", "missing rating"
+		}
+		if *row.Rating < FavoriteRatingThreshold {
+			return "", "rating " + strconv.FormatFloat(float64(*row.Rating), 'f', -1, 32) + " is below the favorite threshold of " + strconv.FormatFloat(FavoriteRatingThreshold, 'f', -1, 64)
+		}
+		return entity.CollectionFavorites, ""
+	default:
+		return "", "watched movies are not imported: there is no watch history collection"
+	}
+}
+
+func isMovieTitleType(titleType string) bool {
+	switch strings.ToLower(strings.ReplaceAll(titleType, " ", "")) {
+	case "movie", "tvmovie", "video":
+		return true
+	default:
+		return false
+	}
+}
+
+type movieMatch struct {
+	movie      *models.Movie
+	candidates []models.Movie
+	by         string
+	similarity *float64
+}
+
+// importMatcher resolves export rows to catalogue movies. Movies are loaded
+// once per TMDB batch and per release year, so large exports cost a handful
+// of queries instead of one per row.
+type importMatcher struct {
+	movieRepo repository.MovieRepository
+	byTMDBID  map[int]models.Movie
+	byYear    map[int][]models.Movie
+}
+
+func (uc *ImportCollectionUseCase) newMatcher(ctx context.Context, rows []models.ImportRow) (*importMatcher, error) {
+	m := &importMatcher{
+		movieRepo: uc.movieRepo,
+		byTMDBID:  make(map[int]models.Movie),
+		byYear:    make(map[int][]models.Movie),
+	}
+
+	tmdbIDs := make([]int, 0)
+	for _, row := range rows {
+		if row.TMDBID > 0 {
+			tmdbIDs = append(tmdbIDs, row.TMDBID)
+		}
+	}
+	if len(tmdbIDs) > 0 {
+		movies, err := uc.movieRepo.GetMoviesByTMDBIDs(ctx, tmdbIDs)
+		if err != nil {
+			uc.logger.Error("Failed to load movies by TMDB ID for import", err)
+			return nil, err
+		}
+		for _, movie := range movies {
+			m.byTMDBID[movie.TMDB_ID] = movie
+		}
+	}
+
+	return m, nil
+}
+
+func (m *importMatcher) moviesForYear(ctx context.Context, year int) ([]models.Movie, error) {
+	if movies, ok := m.byYear[year]; ok {
+		return movies, nil
+	}
+	movies, err := m.movieRepo.GetMoviesByReleaseYear(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	m.byYear[year] = movies
+	return movies, nil
+}
+
+func (m *importMatcher) match(ctx context.Context, row models.ImportRow) (movieMatch, error) {
+	if movie, ok := m.byTMDBID[row.TMDBID]; ok && row.TMDBID > 0 {
+		return movieMatch{movie: &movie, by: MatchedByTMDBID}, nil
+	}
+
+	title := normalizeTitle(row.Title)
+
+	if row.Year == 0 {
+		return m.matchTitle(ctx, row.Title, title)
+	}
+
+	// Exact title in the same year, then in adjacent years since release
+	// years often differ by one between services.
+	sameYear, err := m.moviesForYear(ctx, row.Year)
+	if err != nil {
+		return movieMatch{}, err
+	}
+	if match := pickExact(filterByTitle(sameYear, title), MatchedByTitleYear); match.movie != nil || len(match.candidates) > 0 {
+		return match, nil
+	}
+
+	nearby := make([]models.Movie, 0, len(sameYear))
+	nearby = append(nearby, sameYear...)
+	for _, year := range []int{row.Year - 1, row.Year + 1} {
+		movies, err := m.moviesForYear(ctx, year)
+		if err != nil {
+			return movieMatch{}, err
+		}
+		nearby = append(nearby, movies...)
+	}
+	if match := pickExact(filterByTitle(nearby, title), MatchedByTitleYear); match.movie != nil || len(match.candidates) > 0 {
+		return match, nil
+	}
+
+	return fuzzyMatch(nearby, title), nil
+}
+
+// matchTitle matches a row without a year: the exact title, then a title
+// equal once normalized, then a fuzzy match among movies sharing a word.
+func (m *importMatcher) matchTitle(ctx context.Context, rawTitle, title string) (movieMatch, error) {
+	movies, err := m.movieRepo.FindMoviesByTitle(ctx, rawTitle)
+	if err != nil {
+		return movieMatch{}, err
+	}
+	if match := pickExact(movies, MatchedByTitle); match.movie != nil || len(match.candidates) > 0 {
+		return match, nil
+	}
+
+	words := make([]string, 0)
+	for _, word := range strings.Fields(title) {
+		if len([]rune(word)) >= minTitleWordLength {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return movieMatch{}, nil
+	}
+	candidates, err := m.movieRepo.FindMoviesByTitleWords(ctx, words, fuzzyCandidateLimit)
+	if err != nil {
+		return movieMatch{}, err
+	}
+	if match := pickExact(filterByTitle(candidates, title), MatchedByTitle); match.movie != nil || len(match.candidates) > 0 {
+		return match, nil
+	}
+	return fuzzyMatch(candidates, title), nil
+}
+
+func pickExact(movies []models.Movie, by string) movieMatch {
+	switch len(movies) {
+	case 0:
+		return movieMatch{}
+	case 1:
+		return movieMatch{movie: &movies[0], by: by}
+	default:
+		return movieMatch{candidates: movies}
+	}
+}
+
+func filterByTitle(movies []models.Movie, normalizedTitle string) []models.Movie {
+	result := make([]models.Movie, 0)
+	for _, movie := range movies {
+		if normalizeTitle(movie.Title) == normalizedTitle {
+			result = append(result, movie)
+		}
+	}
+	return result
+}
+
+func fuzzyMatch(movies []models.Movie, normalizedTitle string) movieMatch {
+	var best, runnerUp *models.Movie
+	var bestScore, runnerUpScore float64
+
+	for i := range movies {
+		score := titleSimilarity(normalizeTitle(movies[i].Title), normalizedTitle)
+		if score > bestScore {
+			runnerUp, runnerUpScore = best, bestScore
+			best, bestScore = &movies[i], score
+		} else if score > runnerUpScore {
+			runnerUp, runnerUpScore = &movies[i], score
+		}
+	}
+
+	if best == nil || bestScore < fuzzyMatchThreshold {
+		return movieMatch{}
+	}
+	if runnerUp != nil && runnerUpScore >= fuzzyMatchThreshold && bestScore-runnerUpScore < fuzzyAmbiguityMargin {
+		return movieMatch{candidates: []models.Movie{*best, *runnerUp}}
+	}
+	return movieMatch{movie: best, by: MatchedByFuzzy, similarity: &bestScore}
+}
+
+// normalizeTitle lowercases a title, drops punctuation and a leading
+// article, and collapses whitespace so equivalent titles compare equal.
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	lastSpace := true
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			lastSpace = false
+		case r == '&':
+			if !lastSpace {
+				b.WriteRune(' ')
+			}
+			b.WriteString("and ")
+			lastSpace = true
+		case !lastSpace:
+			b.WriteRune(' ')
+			lastSpace = true
+		}
+	}
+	normalized := strings.TrimSpace(b.String())
+	for _, article := range []string{"the ", "a ", "an "} {
+		normalized = strings.TrimPrefix(normalized, article)
+	}
+	return normalized
+}
+
+// titleSimilarity returns 1 minus the normalized Levenshtein distance.
+func titleSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	longest := max(len(ra), len(rb))
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
@@ -0,0 +1,262 @@
+package account
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+// fakeMovieRepository answers the import lookups from memory the way the
+// Postgres queries do. Methods it does not implement panic through the nil
+// embedded interface.
+type fakeMovieRepository struct {
+	repository.MovieRepository
+	movies      []models.Movie
+	yearQueries map[int]int
+}
+
+func (f *fakeMovieRepository) GetMoviesByTMDBIDs(ctx context.Context, tmdbIDs []int) ([]models.Movie, error) {
+	var movies []models.Movie
+	for _, m := range f.movies {
+		if slices.Contains(tmdbIDs, m.TMDB_ID) {
+			movies = append(movies, m)
+		}
+	}
+	return movies, nil
+}
+
+func (f *fakeMovieRepository) GetMoviesByReleaseYear(ctx context.Context, year int) ([]models.Movie, error) {
+	f.yearQueries[year]++
+	var movies []models.Movie
+	for _, m := range f.movies {
+		if m.ReleaseYear == year {
+			movies = append(movies, m)
+		}
+	}
+	return movies, nil
+}
+
+func (f *fakeMovieRepository) FindMoviesByTitle(ctx context.Context, title string) ([]models.Movie, error) {
+	var movies []models.Movie
+	for _, m := range f.movies {
+		if strings.EqualFold(m.Title, title) {
+			movies = append(movies, m)
+		}
+	}
+	return movies, nil
+}
+
+func (f *fakeMovieRepository) FindMoviesByTitleWords(ctx context.Context, words []string, limit int) ([]models.Movie, error) {
+	var movies []models.Movie
+	for _, m := range f.movies {
+		title := strings.ToLower(m.Title)
+		if slices.ContainsFunc(words, func(word string) bool { return strings.Contains(title, word) }) {
+			movies = append(movies, m)
+		}
+	}
+	return movies[:min(limit, len(movies))], nil
+}
+
+func importCatalogue() *fakeMovieRepository {
+	return &fakeMovieRepository{
+		yearQueries: make(map[int]int),
+		movies: []models.Movie{
+			{ID: 1, TMDB_ID: 129, Title: "Spirited Away", ReleaseYear: 2001},
+			{ID: 2, TMDB_ID: 438631, Title: "Dune", ReleaseYear: 2021},
+			{ID: 3, TMDB_ID: 841, Title: "Dune", ReleaseYear: 1984},
+			{ID: 4, TMDB_ID: 146, Title: "Crouching Tiger, Hidden Dragon", ReleaseYear: 2000},
+			{ID: 5, TMDB_ID: 545611, Title: "Everything Everywhere All at Once", ReleaseYear: 2022},
+			{ID: 6, TMDB_ID: 671, Title: "Harry Potter and the Philosopher's Stone", ReleaseYear: 2001},
+			{ID: 7, TMDB_ID: 120, Title: "The Lord of the Rings: The Fellowship of the Ring", ReleaseYear: 2001},
+			{ID: 8, TMDB_ID: 24, Title: "Kill Bill: Vol. 1", ReleaseYear: 2003},
+			{ID: 9, TMDB_ID: 393, Title: "Kill Bill: Vol. 2", ReleaseYear: 2004},
+			{ID: 10, TMDB_ID: 15472, Title: "The Girl with the Dragon Tattoo", ReleaseYear: 2009},
+			{ID: 11, TMDB_ID: 65754, Title: "The Girl with the Dragon Tattoo", ReleaseYear: 2011},
+			{ID: 12, TMDB_ID: 110, Title: "Three Colours: Red", ReleaseYear: 1994},
+		},
+	}
+}
+
+func testLogger(tb testing.TB) *logger.Logger {
+	tb.Helper()
+	log, err := logger.New(logger.Options{Level: "error"})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return log
+}
+
+func TestImportMatcher(t *testing.T) {
+	tests := []struct {
+		name           string
+		row            models.ImportRow
+		wantMovie      int
+		wantBy         string
+		wantCandidates []int
+	}{
+		{
+			name:      "tmdb id wins over title and year",
+			row:       models.ImportRow{TMDBID: 129, Title: "Sen to Chihiro no kamikakushi", Year: 1999},
+			wantMovie: 1, wantBy: MatchedByTMDBID,
+		},
+		{
+			name:      "unknown tmdb id falls back to title and year",
+			row:       models.ImportRow{TMDBID: 999999, Title: "Spirited Away", Year: 2001},
+			wantMovie: 1, wantBy: MatchedByTitleYear,
+		},
+		{
+			name:      "title and year",
+			row:       models.ImportRow{Title: "Dune", Year: 2021},
+			wantMovie: 2, wantBy: MatchedByTitleYear,
+		},
+		{
+			name:      "punctuation and case are ignored",
+			row:       models.ImportRow{Title: "crouching tiger hidden dragon", Year: 2000},
+			wantMovie: 4, wantBy: MatchedByTitleYear,
+		},
+		{
+			name:      "release year off by one",
+			row:       models.ImportRow{Title: "Everything Everywhere All at Once", Year: 2021},
+			wantMovie: 5, wantBy: MatchedByTitleYear,
+		},
+		{
+			name:      "same year wins over an adjacent year",
+			row:       models.ImportRow{Title: "The Girl with the Dragon Tattoo", Year: 2011},
+			wantMovie: 11, wantBy: MatchedByTitleYear,
+		},
+		{
+			name:           "same title in both adjacent years",
+			row:            models.ImportRow{Title: "The Girl with the Dragon Tattoo", Year: 2010},
+			wantCandidates: []int{10, 11},
+		},
+		{
+			name:      "near-miss title",
+			row:       models.ImportRow{Title: "Harry Potter and the Philosophers Stone", Year: 2001},
+			wantMovie: 6, wantBy: MatchedByFuzzy,
+		},
+		{
+			name:      "near-miss title in an adjacent year",
+			row:       models.ImportRow{Title: "Three Colors: Red", Year: 1995},
+			wantMovie: 12, wantBy: MatchedByFuzzy,
+		},
+		{
+			name:           "near-miss title as close to two movies",
+			row:            models.ImportRow{Title: "Kill Bill: Vol. 3", Year: 2003},
+			wantCandidates: []int{8, 9},
+		},
+		{
+			name: "title too far off",
+			row:  models.ImportRow{Title: "Kill Bill: The Whole Bloody Affair", Year: 2003},
+		},
+		{
+			name: "right title, wrong decade",
+			row:  models.ImportRow{Title: "Kill Bill: Vol. 1", Year: 2013},
+		},
+		{
+			name:      "no year, exact title",
+			row:       models.ImportRow{Title: "spirited away"},
+			wantMovie: 1, wantBy: MatchedByTitle,
+		},
+		{
+			name:           "no year, title of several movies",
+			row:            models.ImportRow{Title: "Dune"},
+			wantCandidates: []int{2, 3},
+		},
+		{
+			name:      "no year, title equal once normalized",
+			row:       models.ImportRow{Title: "Lord of the Rings - The Fellowship of the Ring"},
+			wantMovie: 7, wantBy: MatchedByTitle,
+		},
+		{
+			name:      "no year, near-miss title",
+			row:       models.ImportRow{Title: "Harry Potter & the Philosophers Stone"},
+			wantMovie: 6, wantBy: MatchedByFuzzy,
+		},
+		{
+			name: "no year, only short words",
+			row:  models.ImportRow{Title: "Up"},
+		},
+	}
+
+	uc := NewImportCollectionUseCase(nil, importCatalogue(), testLogger(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := uc.newMatcher(t.Context(), []models.ImportRow{tt.row})
+			if err != nil {
+				t.Fatal(err)
+			}
+			match, err := matcher.match(t.Context(), tt.row)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotMovie := 0
+			if match.movie != nil {
+				gotMovie = match.movie.ID
+			}
+			if gotMovie != tt.wantMovie || match.by != tt.wantBy {
+				t.Errorf("matched movie %d by %q, want %d by %q", gotMovie, match.by, tt.wantMovie, tt.wantBy)
+			}
+			if (match.by == MatchedByFuzzy) != (match.similarity != nil) {
+				t.Errorf("similarity = %v for a match by %q", match.similarity, match.by)
+			}
+
+			var gotCandidates []int
+			for _, candidate := range match.candidates {
+				gotCandidates = append(gotCandidates, candidate.ID)
+			}
+			slices.Sort(gotCandidates)
+			if !slices.Equal(gotCandidates, tt.wantCandidates) {
+				t.Errorf("candidates = %v, want %v", gotCandidates, tt.wantCandidates)
+			}
+		})
+	}
+}
+
+func TestImportMatcherLoadsEachYearOnce(t *testing.T) {
+	repo := importCatalogue()
+	uc := NewImportCollectionUseCase(nil, repo, testLogger(t))
+	rows := []models.ImportRow{
+		{Title: "Harry Potter and the Philosophers Stone", Year: 2001},
+		{Title: "Spirited Away", Year: 2001},
+		{Title: "Crouching Tiger, Hidden Dragon", Year: 2000},
+	}
+
+	matcher, err := uc.newMatcher(t.Context(), rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if _, err := matcher.match(t.Context(), row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for year, queries := range repo.yearQueries {
+		if queries != 1 {
+			t.Errorf("movies of %d loaded %d times", year, queries)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"three colours red", "three colours red", 1},
+		{"three colors red", "three colours red", 1 - 1.0/17},
+		{"kill bill vol 3", "kill bill vol 1", 1 - 1.0/15},
+		{"", "", 1},
+		{"dune", "", 0},
+	}
+	for _, tt := range tests {
+		if got := titleSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("titleSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
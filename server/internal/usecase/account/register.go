@@ -1,9 +1,13 @@
 package account
 
 import (
+	"context"
+	"errors"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/entity"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 	"github.com/jgamaraalv/movies.git/pkg/token"
@@ -22,25 +26,31 @@ type RegisterOutput struct {
 }
 
 type RegisterUseCase struct {
-	userRepo repository.UserRepository
-	logger   *logger.Logger
+	userRepo  repository.UserRepository
+	jwtSecret string
+	logger    *logger.Logger
 }
 
-func NewRegisterUseCase(repo repository.UserRepository, log *logger.Logger) *RegisterUseCase {
+func NewRegisterUseCase(repo repository.UserRepository, jwtSecret string, log *logger.Logger) *RegisterUseCase {
 	return &RegisterUseCase{
-		userRepo: repo,
-		logger:   log,
+		userRepo:  repo,
+		jwtSecret: jwtSecret,
+		logger:    log,
 	}
 }
 
-func (uc *RegisterUseCase) Execute(input RegisterInput) (*RegisterOutput, error) {
-	email, err := valueobject.NewEmail(input.Email)
-	if err != nil {
-		return nil, err
-	}
+func (uc *RegisterUseCase) Execute(ctx context.Context, input RegisterInput) (*RegisterOutput, error) {
+	ctx, span := tracing.Start(ctx, "RegisterUseCase.Execute")
+	defer span.End()
 
-	password, err := valueobject.NewPassword(input.Password)
-	if err != nil {
+	// Validate every field so clients can report all problems at once
+	email, emailErr := valueobject.NewEmail(input.Email)
+	password, passwordErr := valueobject.NewPassword(input.Password)
+	var nameErr error
+	if input.Name == "" {
+		nameErr = repository.ErrNameRequired
+	}
+	if err := errors.Join(nameErr, emailErr, passwordErr); err != nil {
 		return nil, err
 	}
 
@@ -52,20 +62,21 @@ func (uc *RegisterUseCase) Execute(input RegisterInput) (*RegisterOutput, error)
 	hashedPassword, err := user.Password().Hash()
 	if err != nil {
 		uc.logger.Error("Failed to hash password", err)
-		return nil, valueobject.ErrPasswordMismatch
+		return nil, err
 	}
 
-	success, err := uc.userRepo.Register(user.Name(), user.EmailString(), hashedPassword)
+	success, err := uc.userRepo.Register(ctx, user.Name(), user.EmailString(), hashedPassword)
 	if err != nil {
 		return nil, err
 	}
 
 	jwt := token.CreateJWT(
 		models.User{Email: user.EmailString(), Name: user.Name()},
+		uc.jwtSecret,
 		*uc.logger,
 	)
 
-	uc.logger.Info("User registered successfully: " + user.EmailString())
+	uc.logger.Info("User registered successfully", "email", user.EmailString())
 
 	return &RegisterOutput{
 		Success: success,
@@ -1,11 +1,12 @@
 package account
 
 import (
-	"errors"
+	"context"
 
 	"github.com/jgamaraalv/movies.git/internal/domain/entity"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
 	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
@@ -34,21 +35,24 @@ func NewSaveToCollectionUseCase(repo repository.UserRepository, log *logger.Logg
 	}
 }
 
-func (uc *SaveToCollectionUseCase) Execute(input SaveToCollectionInput) (*SaveToCollectionOutput, error) {
+func (uc *SaveToCollectionUseCase) Execute(ctx context.Context, input SaveToCollectionInput) (*SaveToCollectionOutput, error) {
+	ctx, span := tracing.Start(ctx, "SaveToCollectionUseCase.Execute")
+	defer span.End()
+
 	email, err := valueobject.NewEmail(input.Email)
 	if err != nil {
 		return nil, err
 	}
 
 	if input.MovieID <= 0 {
-		return nil, errors.New("invalid movie ID")
+		return nil, repository.ErrInvalidMovieID
 	}
 
 	if input.Collection != entity.CollectionFavorites && input.Collection != entity.CollectionWatchlist {
 		return nil, repository.ErrInvalidCollectionType
 	}
 
-	userModel, err := uc.userRepo.GetAccountDetails(email.String())
+	userModel, err := uc.userRepo.GetAccountDetails(ctx, email.String())
 	if err != nil {
 		uc.logger.Error("Failed to get user details", err)
 		return nil, err
@@ -73,14 +77,14 @@ func (uc *SaveToCollectionUseCase) Execute(input SaveToCollectionInput) (*SaveTo
 	}
 
 	userModelForSave := models.User{Email: email.String()}
-	success, err := uc.userRepo.SaveCollection(userModelForSave, input.MovieID, input.Collection)
+	success, err := uc.userRepo.SaveCollection(ctx, userModelForSave, input.MovieID, input.Collection)
 	if err != nil {
 		uc.logger.Error("Failed to save movie to collection", err)
 		return nil, err
 	}
 
 	message := "Movie added to " + input.Collection + " successfully"
-	uc.logger.Info(message + " for user: " + email.String())
+	uc.logger.Info(message, "email", email.String(), "movie_id", input.MovieID)
 
 	return &SaveToCollectionOutput{
 		Success:             success,
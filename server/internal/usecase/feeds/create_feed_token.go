@@ -0,0 +1,64 @@
+package feeds
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+	"github.com/jgamaraalv/movies.git/pkg/token"
+)
+
+type CreateFeedTokenInput struct {
+	Email string
+}
+
+type CreateFeedTokenOutput struct {
+	Token string
+}
+
+type CreateFeedTokenUseCase struct {
+	feedRepo repository.FeedRepository
+	logger   *logger.Logger
+}
+
+func NewCreateFeedTokenUseCase(repo repository.FeedRepository, log *logger.Logger) *CreateFeedTokenUseCase {
+	return &CreateFeedTokenUseCase{
+		feedRepo: repo,
+		logger:   log,
+	}
+}
+
+// Execute issues a new feed token for the user, replacing (and so revoking)
+// any previous one. The plain token is only ever returned here.
+func (uc *CreateFeedTokenUseCase) Execute(ctx context.Context, input CreateFeedTokenInput) (*CreateFeedTokenOutput, error) {
+	ctx, span := tracing.Start(ctx, "CreateFeedTokenUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uc.feedRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for feed token", err)
+		return nil, err
+	}
+
+	feedToken, err := token.NewFeedToken()
+	if err != nil {
+		uc.logger.Error("Failed to generate feed token", err)
+		return nil, err
+	}
+
+	if err := uc.feedRepo.SaveFeedToken(ctx, userID, token.HashFeedToken(feedToken)); err != nil {
+		uc.logger.Error("Failed to save feed token", err)
+		return nil, err
+	}
+
+	uc.logger.Info("Issued feed token", "user_id", userID)
+
+	return &CreateFeedTokenOutput{Token: feedToken}, nil
+}
@@ -0,0 +1,143 @@
+package feeds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/entity"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+	"github.com/jgamaraalv/movies.git/pkg/token"
+)
+
+const (
+	FeedWatchlist       = "watchlist"
+	FeedRecommendations = "recommendations"
+	// FeedDiary lists watched movies. There is no separate watch diary yet,
+	// so favorites stand in as the movies a user has watched.
+	FeedDiary = "diary"
+)
+
+const feedEntryLimit = 100
+
+type GetPersonalFeedInput struct {
+	Token string
+	Kind  string
+	// IfNoneMatch and IfModifiedSince carry the client's cached version.
+	IfNoneMatch     string
+	IfModifiedSince time.Time
+}
+
+type GetPersonalFeedOutput struct {
+	ETag         string
+	LastModified time.Time
+	NotModified  bool
+	Entries      []models.CollectionEntry
+}
+
+type GetPersonalFeedUseCase struct {
+	feedRepo repository.FeedRepository
+	logger   *logger.Logger
+}
+
+func NewGetPersonalFeedUseCase(repo repository.FeedRepository, log *logger.Logger) *GetPersonalFeedUseCase {
+	return &GetPersonalFeedUseCase{
+		feedRepo: repo,
+		logger:   log,
+	}
+}
+
+func (uc *GetPersonalFeedUseCase) Execute(ctx context.Context, input GetPersonalFeedInput) (*GetPersonalFeedOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetPersonalFeedUseCase.Execute")
+	defer span.End()
+
+	if input.Kind != FeedWatchlist && input.Kind != FeedRecommendations && input.Kind != FeedDiary {
+		return nil, repository.ErrInvalidFeedKind
+	}
+	if input.Token == "" {
+		return nil, repository.ErrFeedTokenNotFound
+	}
+
+	userID, err := uc.feedRepo.GetUserIDByFeedToken(ctx, token.HashFeedToken(input.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	var lastModified time.Time
+	var count int
+	if input.Kind == FeedRecommendations {
+		lastModified, count, err = uc.feedRepo.GetRecommendationsVersion(ctx, userID)
+	} else {
+		lastModified, count, err = uc.feedRepo.GetCollectionVersion(ctx, userID, collectionForFeed(input.Kind))
+	}
+	if err != nil {
+		uc.logger.Error("Failed to get feed version", err, "kind", input.Kind)
+		return nil, err
+	}
+
+	output := &GetPersonalFeedOutput{
+		ETag:         feedETag(userID, input.Kind, lastModified, count),
+		LastModified: lastModified.UTC().Truncate(time.Second),
+	}
+
+	if isNotModified(input, output) {
+		output.NotModified = true
+		return output, nil
+	}
+
+	if input.Kind == FeedRecommendations {
+		output.Entries, err = uc.feedRepo.GetRecommendationEntries(ctx, userID, feedEntryLimit)
+	} else {
+		output.Entries, err = uc.feedRepo.GetCollectionEntries(ctx, userID, collectionForFeed(input.Kind), feedEntryLimit)
+	}
+	if err != nil {
+		uc.logger.Error("Failed to get feed entries", err, "kind", input.Kind)
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func collectionForFeed(kind string) string {
+	if kind == FeedDiary {
+		return entity.CollectionFavorites
+	}
+	return entity.CollectionWatchlist
+}
+
+// feedETag identifies a feed's contents by its newest timestamp and size, so
+// it changes whenever entries are added, removed or recomputed.
+func feedETag(userID int, kind string, lastModified time.Time, count int) string {
+	version := strings.Join([]string{
+		strconv.Itoa(userID),
+		kind,
+		strconv.FormatInt(lastModified.UnixNano(), 10),
+		strconv.Itoa(count),
+	}, ":")
+	sum := sha256.Sum256([]byte(version))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// isNotModified applies RFC 9110 precedence: If-None-Match wins over
+// If-Modified-Since when both are present.
+func isNotModified(input GetPersonalFeedInput, output *GetPersonalFeedOutput) bool {
+	if input.IfNoneMatch != "" {
+		for _, tag := range strings.Split(input.IfNoneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(output.ETag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if !input.IfModifiedSince.IsZero() && !output.LastModified.IsZero() {
+		return !output.LastModified.After(input.IfModifiedSince)
+	}
+	return false
+}
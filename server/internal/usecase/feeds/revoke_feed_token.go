@@ -0,0 +1,61 @@
+package feeds
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type RevokeFeedTokenInput struct {
+	Email string
+}
+
+type RevokeFeedTokenOutput struct {
+	Success bool
+	Message string
+}
+
+type RevokeFeedTokenUseCase struct {
+	feedRepo repository.FeedRepository
+	logger   *logger.Logger
+}
+
+func NewRevokeFeedTokenUseCase(repo repository.FeedRepository, log *logger.Logger) *RevokeFeedTokenUseCase {
+	return &RevokeFeedTokenUseCase{
+		feedRepo: repo,
+		logger:   log,
+	}
+}
+
+func (uc *RevokeFeedTokenUseCase) Execute(ctx context.Context, input RevokeFeedTokenInput) (*RevokeFeedTokenOutput, error) {
+	ctx, span := tracing.Start(ctx, "RevokeFeedTokenUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uc.feedRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for feed token revocation", err)
+		return nil, err
+	}
+
+	revoked, err := uc.feedRepo.RevokeFeedToken(ctx, userID)
+	if err != nil {
+		uc.logger.Error("Failed to revoke feed token", err)
+		return nil, err
+	}
+
+	if !revoked {
+		return &RevokeFeedTokenOutput{Success: true, Message: "No feed token to revoke"}, nil
+	}
+
+	uc.logger.Info("Revoked feed token", "user_id", userID)
+
+	return &RevokeFeedTokenOutput{Success: true, Message: "Feed token revoked"}, nil
+}
@@ -1,7 +1,10 @@
 package movie
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
@@ -22,8 +25,11 @@ func NewGetGenresUseCase(repo repository.MovieRepository, log *logger.Logger) *G
 	}
 }
 
-func (uc *GetGenresUseCase) Execute() (*GetGenresOutput, error) {
-	genres, err := uc.movieRepo.GetAllGenres()
+func (uc *GetGenresUseCase) Execute(ctx context.Context) (*GetGenresOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetGenresUseCase.Execute")
+	defer span.End()
+
+	genres, err := uc.movieRepo.GetAllGenres(ctx)
 	if err != nil {
 		uc.logger.Error("Failed to get genres", err)
 		return nil, err
@@ -1,11 +1,11 @@
 package movie
 
 import (
-	"errors"
-	"strconv"
+	"context"
 
 	"github.com/jgamaraalv/movies.git/internal/domain/entity"
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
@@ -45,14 +45,17 @@ func NewGetMovieByIDUseCase(repo repository.MovieRepository, log *logger.Logger)
 	}
 }
 
-func (uc *GetMovieByIDUseCase) Execute(input GetMovieByIDInput) (*GetMovieByIDOutput, error) {
+func (uc *GetMovieByIDUseCase) Execute(ctx context.Context, input GetMovieByIDInput) (*GetMovieByIDOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetMovieByIDUseCase.Execute")
+	defer span.End()
+
 	if input.ID <= 0 {
-		return nil, errors.New("invalid movie ID")
+		return nil, repository.ErrInvalidMovieID
 	}
 
-	movieModel, err := uc.movieRepo.GetMovieByID(input.ID)
+	movieModel, err := uc.movieRepo.GetMovieByID(ctx, input.ID)
 	if err != nil {
-		uc.logger.Error("Failed to get movie by ID: "+strconv.Itoa(input.ID), err)
+		uc.logger.Error("Failed to get movie by ID", err, "movie_id", input.ID)
 		return nil, err
 	}
 
@@ -77,7 +80,7 @@ func (uc *GetMovieByIDUseCase) Execute(input GetMovieByIDInput) (*GetMovieByIDOu
 		MainCast:       mainCastNames,
 	}
 
-	uc.logger.Info("Successfully retrieved movie with ID: " + strconv.Itoa(input.ID))
+	uc.logger.Info("Successfully retrieved movie", "movie_id", input.ID)
 
 	return &GetMovieByIDOutput{
 		Movie:   movieModel,
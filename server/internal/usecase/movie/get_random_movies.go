@@ -1,11 +1,19 @@
 package movie
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
+type GetRandomMoviesInput struct {
+	// Include lists relations to load onto every movie.
+	Include []repository.MovieRelation
+}
+
 type GetRandomMoviesOutput struct {
 	Movies []models.Movie
 }
@@ -22,12 +30,22 @@ func NewGetRandomMoviesUseCase(repo repository.MovieRepository, log *logger.Logg
 	}
 }
 
-func (uc *GetRandomMoviesUseCase) Execute() (*GetRandomMoviesOutput, error) {
-	movies, err := uc.movieRepo.GetRandomMovies()
+func (uc *GetRandomMoviesUseCase) Execute(ctx context.Context, input GetRandomMoviesInput) (*GetRandomMoviesOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetRandomMoviesUseCase.Execute")
+	defer span.End()
+
+	if err := repository.ValidateMovieRelations(input.Include); err != nil {
+		return nil, err
+	}
+
+	movies, err := uc.movieRepo.GetRandomMovies(ctx)
 	if err != nil {
 		uc.logger.Error("Failed to get random movies", err)
 		return nil, err
 	}
+	if err := loadRelations(ctx, uc.movieRepo, movies, input.Include); err != nil {
+		return nil, err
+	}
 
 	uc.logger.Info("Successfully retrieved random movies")
 
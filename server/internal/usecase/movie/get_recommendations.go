@@ -1,7 +1,10 @@
 package movie
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
@@ -26,30 +29,33 @@ func NewGetRecommendationsUseCase(recRepo repository.RecommendationRepository, l
 	}
 }
 
-func (uc *GetRecommendationsUseCase) Execute(input GetRecommendationsInput) (*GetRecommendationsOutput, error) {
-	userID, err := uc.recRepo.GetUserIDByEmail(input.Email)
+func (uc *GetRecommendationsUseCase) Execute(ctx context.Context, input GetRecommendationsInput) (*GetRecommendationsOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetRecommendationsUseCase.Execute")
+	defer span.End()
+
+	userID, err := uc.recRepo.GetUserIDByEmail(ctx, input.Email)
 	if err != nil {
 		uc.logger.Error("Failed to get user ID for recommendations", err)
-		return &GetRecommendationsOutput{Movies: []models.Movie{}}, nil
+		return nil, err
 	}
 
-	has, err := uc.recRepo.HasRecommendations(userID)
+	has, err := uc.recRepo.HasRecommendations(ctx, userID)
 	if err != nil {
 		uc.logger.Error("Failed to check recommendations", err)
-		return &GetRecommendationsOutput{Movies: []models.Movie{}}, nil
+		return nil, err
 	}
 
 	if !has {
 		return &GetRecommendationsOutput{Movies: []models.Movie{}}, nil
 	}
 
-	movies, err := uc.recRepo.GetRecommendations(userID, 20)
+	movies, err := uc.recRepo.GetRecommendations(ctx, userID, 20)
 	if err != nil {
 		uc.logger.Error("Failed to get recommendations", err)
-		return &GetRecommendationsOutput{Movies: []models.Movie{}}, nil
+		return nil, err
 	}
 
-	uc.logger.Info("Successfully retrieved recommendations for user: " + input.Email)
+	uc.logger.Info("Successfully retrieved recommendations", "email", input.Email)
 
 	return &GetRecommendationsOutput{Movies: movies}, nil
 }
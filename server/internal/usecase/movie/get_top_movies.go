@@ -1,11 +1,19 @@
 package movie
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
+type GetTopMoviesInput struct {
+	// Include lists relations to load onto every movie.
+	Include []repository.MovieRelation
+}
+
 type GetTopMoviesOutput struct {
 	Movies []models.Movie
 }
@@ -22,12 +30,22 @@ func NewGetTopMoviesUseCase(repo repository.MovieRepository, log *logger.Logger)
 	}
 }
 
-func (uc *GetTopMoviesUseCase) Execute() (*GetTopMoviesOutput, error) {
-	movies, err := uc.movieRepo.GetTopMovies()
+func (uc *GetTopMoviesUseCase) Execute(ctx context.Context, input GetTopMoviesInput) (*GetTopMoviesOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetTopMoviesUseCase.Execute")
+	defer span.End()
+
+	if err := repository.ValidateMovieRelations(input.Include); err != nil {
+		return nil, err
+	}
+
+	movies, err := uc.movieRepo.GetTopMovies(ctx)
 	if err != nil {
 		uc.logger.Error("Failed to get top movies", err)
 		return nil, err
 	}
+	if err := loadRelations(ctx, uc.movieRepo, movies, input.Include); err != nil {
+		return nil, err
+	}
 
 	uc.logger.Info("Successfully retrieved top movies")
 
@@ -0,0 +1,17 @@
+package movie
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/models"
+)
+
+// loadRelations adds the requested relations to a list of movies. Without
+// any, list movies keep their relations unset, as they always have.
+func loadRelations(ctx context.Context, repo repository.MovieRepository, movies []models.Movie, include []repository.MovieRelation) error {
+	if len(include) == 0 {
+		return nil
+	}
+	return repo.LoadRelations(ctx, movies, include)
+}
@@ -1,9 +1,10 @@
 package movie
 
 import (
-	"errors"
+	"context"
 
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/models"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
@@ -12,6 +13,8 @@ type SearchMoviesInput struct {
 	Query string
 	Order string
 	Genre *int
+	// Include lists relations to load onto every movie.
+	Include []repository.MovieRelation
 }
 
 type SearchMoviesOutput struct {
@@ -30,18 +33,28 @@ func NewSearchMoviesUseCase(repo repository.MovieRepository, log *logger.Logger)
 	}
 }
 
-func (uc *SearchMoviesUseCase) Execute(input SearchMoviesInput) (*SearchMoviesOutput, error) {
-	if input.Query == "" {
-		return nil, errors.New("search query is required")
+func (uc *SearchMoviesUseCase) Execute(ctx context.Context, input SearchMoviesInput) (*SearchMoviesOutput, error) {
+	ctx, span := tracing.Start(ctx, "SearchMoviesUseCase.Execute")
+	defer span.End()
+
+	// A genre on its own browses that genre
+	if input.Query == "" && input.Genre == nil {
+		return nil, repository.ErrSearchQueryRequired
+	}
+	if err := repository.ValidateMovieRelations(input.Include); err != nil {
+		return nil, err
 	}
 
-	movies, err := uc.movieRepo.SearchMoviesByName(input.Query, input.Order, input.Genre)
+	movies, err := uc.movieRepo.SearchMoviesByName(ctx, input.Query, input.Order, input.Genre)
 	if err != nil {
 		uc.logger.Error("Failed to search movies", err)
 		return nil, err
 	}
+	if err := loadRelations(ctx, uc.movieRepo, movies, input.Include); err != nil {
+		return nil, err
+	}
 
-	uc.logger.Info("Successfully searched movies with query: " + input.Query)
+	uc.logger.Info("Successfully searched movies", "query", input.Query)
 
 	return &SearchMoviesOutput{
 		Movies: movies,
@@ -1,7 +1,10 @@
 package movie
 
 import (
+	"context"
+
 	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
@@ -25,24 +28,27 @@ func NewUpdateUserRecommendationsUseCase(recRepo repository.RecommendationReposi
 	}
 }
 
-func (uc *UpdateUserRecommendationsUseCase) Execute(input UpdateUserRecommendationsInput) (*UpdateUserRecommendationsOutput, error) {
-	userID, err := uc.recRepo.GetUserIDByEmail(input.Email)
+func (uc *UpdateUserRecommendationsUseCase) Execute(ctx context.Context, input UpdateUserRecommendationsInput) (*UpdateUserRecommendationsOutput, error) {
+	ctx, span := tracing.Start(ctx, "UpdateUserRecommendationsUseCase.Execute")
+	defer span.End()
+
+	userID, err := uc.recRepo.GetUserIDByEmail(ctx, input.Email)
 	if err != nil {
 		uc.logger.Error("Failed to get user ID for recommendation update", err)
 		return &UpdateUserRecommendationsOutput{Success: false}, err
 	}
 
-	if err := uc.recRepo.RecomputeUserEmbedding(userID); err != nil {
+	if err := uc.recRepo.RecomputeUserEmbedding(ctx, userID); err != nil {
 		uc.logger.Error("Failed to recompute user embedding", err)
 		// Continue even if embedding fails — genre-based recs still work
 	}
 
-	if err := uc.recRepo.ComputeRecommendations(userID); err != nil {
+	if err := uc.recRepo.ComputeRecommendations(ctx, userID); err != nil {
 		uc.logger.Error("Failed to compute recommendations", err)
 		return &UpdateUserRecommendationsOutput{Success: false}, err
 	}
 
-	uc.logger.Info("Successfully updated recommendations for user: " + input.Email)
+	uc.logger.Info("Successfully updated recommendations", "email", input.Email)
 
 	return &UpdateUserRecommendationsOutput{Success: true}, nil
 }
@@ -0,0 +1,39 @@
+package sitemap
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type GetGenreSitemapOutput struct {
+	Genres []models.SitemapEntry
+}
+
+type GetGenreSitemapUseCase struct {
+	sitemapRepo repository.SitemapRepository
+	logger      *logger.Logger
+}
+
+func NewGetGenreSitemapUseCase(repo repository.SitemapRepository, log *logger.Logger) *GetGenreSitemapUseCase {
+	return &GetGenreSitemapUseCase{
+		sitemapRepo: repo,
+		logger:      log,
+	}
+}
+
+func (uc *GetGenreSitemapUseCase) Execute(ctx context.Context) (*GetGenreSitemapOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetGenreSitemapUseCase.Execute")
+	defer span.End()
+
+	genres, err := uc.sitemapRepo.GetGenres(ctx)
+	if err != nil {
+		uc.logger.Error("Failed to get genre sitemap", err)
+		return nil, err
+	}
+
+	return &GetGenreSitemapOutput{Genres: genres}, nil
+}
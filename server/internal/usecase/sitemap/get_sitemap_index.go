@@ -0,0 +1,57 @@
+// Package sitemap lists the public catalogue pages for search engines:
+// every movie and every genre, with when each last changed.
+package sitemap
+
+import (
+	"context"
+	"time"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type GetSitemapIndexInput struct {
+	// PageSize is the number of movies per movie sitemap.
+	PageSize int
+}
+
+type GetSitemapIndexOutput struct {
+	// MoviePages holds, for each movie sitemap in order, when its latest
+	// movie changed.
+	MoviePages []time.Time
+	// LastModified is when the catalogue last changed, or zero when it is
+	// empty.
+	LastModified time.Time
+}
+
+type GetSitemapIndexUseCase struct {
+	sitemapRepo repository.SitemapRepository
+	logger      *logger.Logger
+}
+
+func NewGetSitemapIndexUseCase(repo repository.SitemapRepository, log *logger.Logger) *GetSitemapIndexUseCase {
+	return &GetSitemapIndexUseCase{
+		sitemapRepo: repo,
+		logger:      log,
+	}
+}
+
+func (uc *GetSitemapIndexUseCase) Execute(ctx context.Context, input GetSitemapIndexInput) (*GetSitemapIndexOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetSitemapIndexUseCase.Execute")
+	defer span.End()
+
+	pages, err := uc.sitemapRepo.GetMoviePages(ctx, input.PageSize)
+	if err != nil {
+		uc.logger.Error("Failed to get movie sitemap pages", err)
+		return nil, err
+	}
+
+	output := &GetSitemapIndexOutput{MoviePages: pages}
+	for _, lastModified := range pages {
+		if lastModified.After(output.LastModified) {
+			output.LastModified = lastModified
+		}
+	}
+	return output, nil
+}
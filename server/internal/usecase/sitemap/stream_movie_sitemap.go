@@ -0,0 +1,60 @@
+package sitemap
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type StreamMovieSitemapInput struct {
+	// Page is zero-based.
+	Page     int
+	PageSize int
+	// Write receives each movie in turn, straight from the query.
+	Write func(models.SitemapEntry) error
+}
+
+type StreamMovieSitemapOutput struct {
+	Count int
+}
+
+type StreamMovieSitemapUseCase struct {
+	sitemapRepo repository.SitemapRepository
+	logger      *logger.Logger
+}
+
+func NewStreamMovieSitemapUseCase(repo repository.SitemapRepository, log *logger.Logger) *StreamMovieSitemapUseCase {
+	return &StreamMovieSitemapUseCase{
+		sitemapRepo: repo,
+		logger:      log,
+	}
+}
+
+// Execute returns ErrSitemapNotFound for a page past the last movie, having
+// written nothing.
+func (uc *StreamMovieSitemapUseCase) Execute(ctx context.Context, input StreamMovieSitemapInput) (*StreamMovieSitemapOutput, error) {
+	ctx, span := tracing.Start(ctx, "StreamMovieSitemapUseCase.Execute")
+	defer span.End()
+
+	if input.Page < 0 {
+		return nil, repository.ErrSitemapNotFound
+	}
+
+	count := 0
+	err := uc.sitemapRepo.StreamMoviePage(ctx, input.Page, input.PageSize, func(entry models.SitemapEntry) error {
+		count++
+		return input.Write(entry)
+	})
+	if err != nil {
+		uc.logger.Error("Failed to stream movie sitemap", err, "page", input.Page)
+		return nil, err
+	}
+	if count == 0 {
+		return nil, repository.ErrSitemapNotFound
+	}
+
+	return &StreamMovieSitemapOutput{Count: count}, nil
+}
@@ -0,0 +1,78 @@
+package social
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type FollowUserInput struct {
+	Email      string
+	FolloweeID int
+}
+
+type FollowUserOutput struct {
+	Success          bool
+	Message          string
+	AlreadyFollowing bool
+}
+
+type FollowUserUseCase struct {
+	socialRepo repository.SocialRepository
+	logger     *logger.Logger
+}
+
+func NewFollowUserUseCase(repo repository.SocialRepository, log *logger.Logger) *FollowUserUseCase {
+	return &FollowUserUseCase{
+		socialRepo: repo,
+		logger:     log,
+	}
+}
+
+func (uc *FollowUserUseCase) Execute(ctx context.Context, input FollowUserInput) (*FollowUserOutput, error) {
+	ctx, span := tracing.Start(ctx, "FollowUserUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.FolloweeID <= 0 {
+		return nil, repository.ErrUserNotFound
+	}
+
+	followerID, err := uc.socialRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for follow", err)
+		return nil, err
+	}
+
+	if followerID == input.FolloweeID {
+		return nil, repository.ErrCannotFollowSelf
+	}
+
+	created, err := uc.socialRepo.Follow(ctx, followerID, input.FolloweeID)
+	if err != nil {
+		uc.logger.Error("Failed to follow user", err)
+		return nil, err
+	}
+
+	if !created {
+		return &FollowUserOutput{
+			Success:          true,
+			Message:          "Already following user",
+			AlreadyFollowing: true,
+		}, nil
+	}
+
+	uc.logger.Info("User followed", "follower_id", followerID, "followee_id", input.FolloweeID)
+
+	return &FollowUserOutput{
+		Success: true,
+		Message: "User followed successfully",
+	}, nil
+}
@@ -0,0 +1,74 @@
+package social
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type GetFeedInput struct {
+	Email  string
+	Before int64
+	Limit  int
+}
+
+type GetFeedOutput struct {
+	Events     []models.ActivityEvent
+	NextCursor int64
+}
+
+type GetFeedUseCase struct {
+	socialRepo repository.SocialRepository
+	logger     *logger.Logger
+}
+
+func NewGetFeedUseCase(repo repository.SocialRepository, log *logger.Logger) *GetFeedUseCase {
+	return &GetFeedUseCase{
+		socialRepo: repo,
+		logger:     log,
+	}
+}
+
+func (uc *GetFeedUseCase) Execute(ctx context.Context, input GetFeedInput) (*GetFeedOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetFeedUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uc.socialRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for feed", err)
+		return nil, err
+	}
+
+	before := input.Before
+	if before < 0 {
+		before = 0
+	}
+	limit := normalizeLimit(input.Limit)
+
+	events, err := uc.socialRepo.GetFeed(ctx, userID, before, limit)
+	if err != nil {
+		uc.logger.Error("Failed to get activity feed", err)
+		return nil, err
+	}
+
+	// A full page means there may be older events; the last ID is the cursor
+	// for the next page since the feed is ordered by descending event ID.
+	var nextCursor int64
+	if len(events) == limit {
+		nextCursor = events[len(events)-1].ID
+	}
+
+	return &GetFeedOutput{
+		Events:     events,
+		NextCursor: nextCursor,
+	}, nil
+}
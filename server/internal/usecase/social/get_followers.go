@@ -0,0 +1,56 @@
+package social
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type GetFollowersInput struct {
+	UserID int
+	Limit  int
+	Offset int
+}
+
+type GetFollowersOutput struct {
+	Users []models.UserSummary
+}
+
+type GetFollowersUseCase struct {
+	socialRepo repository.SocialRepository
+	logger     *logger.Logger
+}
+
+func NewGetFollowersUseCase(repo repository.SocialRepository, log *logger.Logger) *GetFollowersUseCase {
+	return &GetFollowersUseCase{
+		socialRepo: repo,
+		logger:     log,
+	}
+}
+
+func (uc *GetFollowersUseCase) Execute(ctx context.Context, input GetFollowersInput) (*GetFollowersOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetFollowersUseCase.Execute")
+	defer span.End()
+
+	if input.UserID <= 0 {
+		return nil, repository.ErrUserNotFound
+	}
+
+	offset := input.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, err := uc.socialRepo.GetFollowers(ctx, input.UserID, normalizeLimit(input.Limit), offset)
+	if err != nil {
+		uc.logger.Error("Failed to get followers", err, "user_id", input.UserID)
+		return nil, err
+	}
+
+	return &GetFollowersOutput{
+		Users: users,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package social
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type GetFollowingInput struct {
+	UserID int
+	Limit  int
+	Offset int
+}
+
+type GetFollowingOutput struct {
+	Users []models.UserSummary
+}
+
+type GetFollowingUseCase struct {
+	socialRepo repository.SocialRepository
+	logger     *logger.Logger
+}
+
+func NewGetFollowingUseCase(repo repository.SocialRepository, log *logger.Logger) *GetFollowingUseCase {
+	return &GetFollowingUseCase{
+		socialRepo: repo,
+		logger:     log,
+	}
+}
+
+func (uc *GetFollowingUseCase) Execute(ctx context.Context, input GetFollowingInput) (*GetFollowingOutput, error) {
+	ctx, span := tracing.Start(ctx, "GetFollowingUseCase.Execute")
+	defer span.End()
+
+	if input.UserID <= 0 {
+		return nil, repository.ErrUserNotFound
+	}
+
+	offset := input.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	users, err := uc.socialRepo.GetFollowing(ctx, input.UserID, normalizeLimit(input.Limit), offset)
+	if err != nil {
+		uc.logger.Error("Failed to get following", err, "user_id", input.UserID)
+		return nil, err
+	}
+
+	return &GetFollowingOutput{
+		Users: users,
+	}, nil
+}
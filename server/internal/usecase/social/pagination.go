@@ -0,0 +1,17 @@
+package social
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalizeLimit clamps a client-supplied page size to a sane range.
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,68 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/entity"
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/models"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type RecordActivityInput struct {
+	Email     string
+	EventType string
+	MovieID   int
+	Payload   json.RawMessage
+}
+
+type RecordActivityUseCase struct {
+	socialRepo repository.SocialRepository
+	logger     *logger.Logger
+}
+
+func NewRecordActivityUseCase(repo repository.SocialRepository, log *logger.Logger) *RecordActivityUseCase {
+	return &RecordActivityUseCase{
+		socialRepo: repo,
+		logger:     log,
+	}
+}
+
+func (uc *RecordActivityUseCase) Execute(ctx context.Context, input RecordActivityInput) error {
+	ctx, span := tracing.Start(ctx, "RecordActivityUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return err
+	}
+
+	if !entity.IsValidActivityType(input.EventType) {
+		return repository.ErrInvalidActivityType
+	}
+
+	userID, err := uc.socialRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for activity", err)
+		return err
+	}
+
+	event := models.ActivityEvent{
+		UserID:    userID,
+		EventType: input.EventType,
+		Payload:   input.Payload,
+	}
+	if input.MovieID > 0 {
+		movieID := input.MovieID
+		event.MovieID = &movieID
+	}
+
+	if err := uc.socialRepo.RecordActivity(ctx, event); err != nil {
+		uc.logger.Error("Failed to record activity", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package social
+
+import (
+	"context"
+
+	"github.com/jgamaraalv/movies.git/internal/domain/repository"
+	"github.com/jgamaraalv/movies.git/internal/domain/valueobject"
+	"github.com/jgamaraalv/movies.git/internal/tracing"
+	"github.com/jgamaraalv/movies.git/pkg/logger"
+)
+
+type UnfollowUserInput struct {
+	Email      string
+	FolloweeID int
+}
+
+type UnfollowUserOutput struct {
+	Success bool
+	Message string
+}
+
+type UnfollowUserUseCase struct {
+	socialRepo repository.SocialRepository
+	logger     *logger.Logger
+}
+
+func NewUnfollowUserUseCase(repo repository.SocialRepository, log *logger.Logger) *UnfollowUserUseCase {
+	return &UnfollowUserUseCase{
+		socialRepo: repo,
+		logger:     log,
+	}
+}
+
+func (uc *UnfollowUserUseCase) Execute(ctx context.Context, input UnfollowUserInput) (*UnfollowUserOutput, error) {
+	ctx, span := tracing.Start(ctx, "UnfollowUserUseCase.Execute")
+	defer span.End()
+
+	email, err := valueobject.NewEmail(input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	followerID, err := uc.socialRepo.GetUserIDByEmail(ctx, email.String())
+	if err != nil {
+		uc.logger.Error("Failed to get user ID for unfollow", err)
+		return nil, err
+	}
+
+	removed, err := uc.socialRepo.Unfollow(ctx, followerID, input.FolloweeID)
+	if err != nil {
+		uc.logger.Error("Failed to unfollow user", err)
+		return nil, err
+	}
+
+	if !removed {
+		return &UnfollowUserOutput{
+			Success: true,
+			Message: "Not following user",
+		}, nil
+	}
+
+	uc.logger.Info("User unfollowed", "follower_id", followerID, "followee_id", input.FolloweeID)
+
+	return &UnfollowUserOutput{
+		Success: true,
+		Message: "User unfollowed successfully",
+	}, nil
+}
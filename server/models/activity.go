@@ -0,0 +1,17 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ActivityEvent struct {
+	ID          int64           `json:"id"`
+	UserID      int             `json:"user_id"`
+	UserName    string          `json:"user_name,omitempty"`
+	EventType   string          `json:"event_type"`
+	MovieID     *int            `json:"-"`
+	Movie       *Movie          `json:"movie,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	TimeCreated time.Time       `json:"time_created"`
+}
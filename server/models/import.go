@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// ImportRow is a single entry read from an external service export.
+type ImportRow struct {
+	Line      int
+	Source    string
+	List      string
+	Title     string
+	Year      int
+	TMDBID    int
+	TitleType string
+	// Collection is set when the export names the collection itself, as
+	// our own CSV and JSON exports do.
+	Collection string
+	Rating     *float32
+	Date       *time.Time
+}
+
+type ImportRowResult struct {
+	Line       int      `json:"line"`
+	List       string   `json:"list"`
+	Title      string   `json:"title"`
+	Year       int      `json:"year,omitempty"`
+	Collection string   `json:"collection,omitempty"`
+	MatchedBy  string   `json:"matched_by,omitempty"`
+	Movie      *Movie   `json:"movie,omitempty"`
+	Candidates []Movie  `json:"candidates,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	Similarity *float64 `json:"similarity,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Matched   []ImportRowResult `json:"matched"`
+	Ambiguous []ImportRowResult `json:"ambiguous"`
+	Unmatched []ImportRowResult `json:"unmatched"`
+	Skipped   []ImportRowResult `json:"skipped"`
+	Imported  int               `json:"imported"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SitemapEntry is a catalogue page listed in a sitemap: the ID of the movie
+// or genre it shows and when that last changed. LastModified is zero when
+// unknown, e.g. for a genre without movies.
+type SitemapEntry struct {
+	ID           int
+	LastModified time.Time
+}
@@ -0,0 +1,57 @@
+package models
+
+type GenreStat struct {
+	GenreID int     `json:"genre_id"`
+	Name    string  `json:"name"`
+	Count   int     `json:"count"`
+	Share   float64 `json:"share"`
+}
+
+type DecadeStat struct {
+	Decade int `json:"decade"`
+	Count  int `json:"count"`
+}
+
+type ActorStat struct {
+	ActorID   int     `json:"actor_id"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	ImageURL  *string `json:"image_url,omitempty"`
+	Count     int     `json:"count"`
+}
+
+type ScoreComparison struct {
+	FavoritesAverage *float64 `json:"favorites_average,omitempty"`
+	CatalogueAverage *float64 `json:"catalogue_average,omitempty"`
+}
+
+type GrowthPoint struct {
+	Month      string `json:"month"`
+	Added      int    `json:"added"`
+	Cumulative int    `json:"cumulative"`
+}
+
+type CollectionTotals struct {
+	Favorites int `json:"favorites"`
+	Watchlist int `json:"watchlist"`
+	Movies    int `json:"movies"`
+}
+
+// StatsSummary holds the "wrapped"-style highlights of a stats report.
+type StatsSummary struct {
+	TopGenre     *GenreStat   `json:"top_genre,omitempty"`
+	TopDecade    *DecadeStat  `json:"top_decade,omitempty"`
+	TopActor     *ActorStat   `json:"top_actor,omitempty"`
+	BusiestMonth *GrowthPoint `json:"busiest_month,omitempty"`
+}
+
+type UserStats struct {
+	Year              int              `json:"year,omitempty"`
+	Totals            CollectionTotals `json:"totals"`
+	GenreDistribution []GenreStat      `json:"genre_distribution"`
+	FavoriteDecades   []DecadeStat     `json:"favorite_decades"`
+	TopActors         []ActorStat      `json:"top_actors"`
+	Scores            ScoreComparison  `json:"scores"`
+	Growth            []GrowthPoint    `json:"growth"`
+	Summary           StatsSummary     `json:"summary"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type User struct {
 	ID        int    `json:"id"`
 	Name      string `json:"name"`
@@ -8,3 +10,22 @@ type User struct {
 	Favorites []Movie
 	Watchlist []Movie
 }
+
+type UserSummary struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type CollectionItem struct {
+	MovieID    int
+	Collection string
+	TimeAdded  time.Time
+}
+
+// CollectionEntry is a movie in one of a user's collections, as read for
+// exports. Movie only carries the fields needed to identify it elsewhere.
+type CollectionEntry struct {
+	Collection string
+	TimeAdded  time.Time
+	Movie      Movie
+}
@@ -1,37 +1,165 @@
+// Package logger provides the service's structured logger, a thin wrapper
+// around log/slog that writes JSON (or text) records to the configured sinks
+// and redacts emails and tokens before they are written.
 package logger
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Options configures New. Zero values mean info level, JSON format and
+// stdout only.
+type Options struct {
+	Level string // debug, info, warn or error
+	// Format is json or text.
+	Format string
+	// Outputs lists the sinks records go to: stdout, stderr and/or file.
+	Outputs []string
+	// FilePath is the log file used by the file output.
+	FilePath string
+	// Redact masks emails and tokens in messages and field values.
+	Redact bool
+}
+
 type Logger struct {
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	file        *os.File
+	slog *slog.Logger
+	file *os.File
 }
 
-func NewLogger(logFilePath string) (*Logger, error) {
-	file, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+// New builds a Logger from opts. It fails if the level, format or an output
+// is unknown or the log file cannot be opened.
+func New(opts Options) (*Logger, error) {
+	level, err := ParseLevel(opts.Level)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Logger{
-		infoLogger:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		errorLogger: log.New(file, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-		file:        file,
-	}, nil
+	outputs := opts.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{OutputStdout}
+	}
+
+	var file *os.File
+	writers := make([]io.Writer, 0, len(outputs))
+	for _, output := range outputs {
+		switch output {
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputStderr:
+			writers = append(writers, os.Stderr)
+		case OutputFile:
+			if file == nil {
+				file, err = os.OpenFile(opts.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+				if err != nil {
+					return nil, err
+				}
+			}
+			writers = append(writers, file)
+		default:
+			return nil, fmt.Errorf("logger: unknown output %q", output)
+		}
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+	}
+	if opts.Redact {
+		handlerOpts.ReplaceAttr = redactAttr
+	}
+
+	w := io.MultiWriter(writers...)
+	var h slog.Handler
+	switch opts.Format {
+	case "", FormatJSON:
+		h = slog.NewJSONHandler(w, handlerOpts)
+	case FormatText:
+		h = slog.NewTextHandler(w, handlerOpts)
+	default:
+		if file != nil {
+			file.Close()
+		}
+		return nil, fmt.Errorf("logger: unknown format %q", opts.Format)
+	}
+
+	return &Logger{slog: slog.New(h), file: file}, nil
 }
 
-func (l *Logger) Info(msg string) {
-	l.infoLogger.Printf("%s", msg)
+// ParseLevel maps a level name to its slog level; empty means info.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("logger: unknown level %q", level)
+	}
 }
 
-func (l *Logger) Error(msg string, err error) {
-	l.errorLogger.Printf("%s: %v", msg, err)
+// With returns a logger that adds the given key/value pairs to every record.
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{slog: l.slog.With(args...)}
 }
 
+func (l *Logger) Debug(msg string, args ...any) {
+	l.log(slog.LevelDebug, msg, args...)
+}
+
+func (l *Logger) Info(msg string, args ...any) {
+	l.log(slog.LevelInfo, msg, args...)
+}
+
+func (l *Logger) Warn(msg string, args ...any) {
+	l.log(slog.LevelWarn, msg, args...)
+}
+
+// Error logs msg at error level with err under the "error" key, followed by
+// any extra key/value pairs.
+func (l *Logger) Error(msg string, err error, args ...any) {
+	if err != nil {
+		args = append([]any{slog.String("error", err.Error())}, args...)
+	}
+	l.log(slog.LevelError, msg, args...)
+}
+
+// log records the caller of the exported method as the source, not this file.
+func (l *Logger) log(level slog.Level, msg string, args ...any) {
+	ctx := context.Background()
+	if !l.slog.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = l.slog.Handler().Handle(ctx, r)
+}
+
+// Close closes the log file, if any. Loggers derived with With share the
+// root's file and do not close it.
 func (l *Logger) Close() {
-	l.file.Close()
+	if l.file != nil {
+		l.file.Close()
+	}
 }
@@ -8,9 +8,8 @@ import (
 	"github.com/jgamaraalv/movies.git/pkg/logger"
 )
 
-func CreateJWT(user models.User, log logger.Logger) string {
-	jwtSecret := GetJWTSecret(log)
-
+// CreateJWT signs a 72h token for user with the configured secret.
+func CreateJWT(user models.User, jwtSecret string, log logger.Logger) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"id":    user.ID,
 		"email": user.Email,
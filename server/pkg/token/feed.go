@@ -0,0 +1,23 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewFeedToken returns a random URL-safe token for personal feed URLs.
+func NewFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashFeedToken returns the form of a feed token that is stored and looked up.
+func HashFeedToken(feedToken string) string {
+	sum := sha256.Sum256([]byte(feedToken))
+	return hex.EncodeToString(sum[:])
+}
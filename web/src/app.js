@@ -98,7 +98,7 @@ window.app = {
           app.Store.jwt = response.jwt;
           app.Router.go("/account/");
         } else {
-          errorEl.textContent = response.message;
+          errorEl.textContent = API.errorMessage(response);
         }
       } finally {
         btn.disabled = false;
@@ -130,7 +130,7 @@ window.app = {
           app.Store.jwt = response.jwt;
           app.Router.go("/account/");
         } else {
-          errorEl.textContent = response.message;
+          errorEl.textContent = API.errorMessage(response);
         }
       } finally {
         btn.disabled = false;
@@ -168,6 +168,22 @@ window.app = {
   },
 };
 
+// Markup names the app method to call in data-on-submit, data-on-click and
+// data-on-change instead of inline on* attributes, which the
+// Content-Security-Policy does not allow.
+document.addEventListener("submit", (event) => {
+  const action = event.target.dataset.onSubmit;
+  if (action) app[action](event);
+});
+document.addEventListener("click", (event) => {
+  const target = event.target.closest("[data-on-click]");
+  if (target) app[target.dataset.onClick](event);
+});
+document.addEventListener("change", (event) => {
+  const action = event.target.dataset.onChange;
+  if (action) app[action](event.target.value);
+});
+
 window.addEventListener("DOMContentLoaded", () => {
   app.Router.init();
   if ("serviceWorker" in navigator) {
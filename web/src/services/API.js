@@ -1,5 +1,5 @@
 export const API = {
-  baseURL: "/api/",
+  baseURL: "/api/v1/",
   getTopMovies: async () => {
     return await API.fetch("movies/top");
   },
@@ -37,16 +37,25 @@ export const API = {
     }
   },
   saveToCollection: async (movie_id, collection) => {
-    return await API.send("account/save-to-collection/", {
+    return await API.send("account/save-to-collection", {
       movie_id,
       collection,
     });
   },
   register: async (name, email, password) => {
-    return await API.send("account/register/", { name, email, password });
+    return await API.send("account/register", { name, email, password });
   },
   authenticate: async (email, password) => {
-    return await API.send("account/authenticate/", { email, password });
+    return await API.send("account/authenticate", { email, password });
+  },
+  // Errors come back as RFC 7807 problem details; validation problems list
+  // each invalid field.
+  errorMessage: (problem) => {
+    if (!problem) return "Something went wrong. Please try again.";
+    if (problem.errors && problem.errors.length > 0) {
+      return problem.errors.map((e) => e.message).join(". ");
+    }
+    return problem.detail || problem.title;
   },
   send: async (serviceName, data) => {
     try {
@@ -79,6 +88,10 @@ export const API = {
         app.showOffline();
         return;
       }
+      if (!response.ok) {
+        console.error(await response.json());
+        return;
+      }
       const result = await response.json();
       return result;
     } catch (e) {